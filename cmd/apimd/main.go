@@ -0,0 +1,109 @@
+// Command apimd runs the generator commands for a definitions package, so that services don't need a main.go of
+// their own. The package has to export a function which returns the definitions, eg.:
+//
+//	func Definitions() generator.Definitons
+//
+// Usage:
+//
+//	apimd [-pkg ./apimd] [-func Definitions] <command> [command flags]
+//
+// The commands are the ones of generator.Main. Commands which need the definitions are run through a small
+// generated main package, which imports the definitions package, so it must be run inside the module of the service.
+// The serve command is restarted whenever a go file of the definitions package or of its dependencies within the
+// module changes, and the open pages reload themselves.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/proemergotech/apimd-generator/generator"
+)
+
+func main() {
+	log.SetFlags(0)
+
+	fs := flag.NewFlagSet("apimd", flag.ExitOnError)
+	pkg := fs.String("pkg", "./apimd", "import path or directory of the definitions package")
+	fn := fs.String("func", "Definitions", "exported function of the package, which returns the definitions")
+	options := fs.String("options", "", "exported function of the package, which returns the generator options ([]generator.Option)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: apimd [flags] <command> [command flags]\n\n"+
+			"commands: generate, check, lint, diff, export, convert, serve, site, collection, client, typescript, schema, mock\n"+
+			"run a command with -h for its flags\n\nflags:")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(os.Args[1:])
+
+	args := fs.Args()
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if !needsDefinitions(args) {
+		err := generator.NewGenerator().Run(nil, args)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		return
+	}
+
+	s, err := newShim(*pkg, *fn, *options)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	if args[0] == "serve" {
+		err = s.serve(args)
+	} else {
+		err = s.run(args)
+	}
+	s.close()
+	if exitErr, ok := errors.Cause(err).(*exec.ExitError); ok {
+		// the shim already reported the error
+		os.Exit(exitErr.ExitCode())
+	}
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+}
+
+// needsDefinitions reports whether the command works with the current definitions, or with document files only.
+// The flags of diff are all boolean, so every other argument is a document file.
+func needsDefinitions(args []string) bool {
+	positional := 0
+	list := false
+	for i, arg := range args[1:] {
+		if arg == "--" {
+			positional += len(args) - i - 2
+			break
+		}
+		switch strings.TrimLeft(arg, "-") {
+		case "list", "list=true":
+			list = true
+		case "list=false":
+			list = false
+		default:
+			if !strings.HasPrefix(arg, "-") || arg == "-" {
+				positional++
+			}
+		}
+	}
+
+	switch args[0] {
+	case "convert":
+		return false
+	case "diff":
+		return positional < 2
+	case "lint":
+		return !list
+	default:
+		return true
+	}
+}
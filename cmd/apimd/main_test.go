@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNeedsDefinitions(t *testing.T) {
+	for _, data := range []struct {
+		Args string
+		Want bool
+	}{
+		{Args: "generate", Want: true},
+		{Args: "check -data-structures", Want: true},
+		{Args: "convert -to json API.md", Want: false},
+		{Args: "diff old.md", Want: true},
+		{Args: "diff -json old.md", Want: true},
+		{Args: "diff -allow-breaking old.md new.json", Want: false},
+		{Args: "diff -- -old.md new.md", Want: false},
+		{Args: "lint", Want: true},
+		{Args: "lint -rule route-description=off", Want: true},
+		{Args: "lint -list", Want: false},
+		{Args: "lint --list=true", Want: false},
+		{Args: "lint -list=false", Want: true},
+	} {
+		if got := needsDefinitions(strings.Fields(data.Args)); got != data.Want {
+			t.Errorf("%v: want %v, got %v", data.Args, data.Want, got)
+		}
+	}
+}
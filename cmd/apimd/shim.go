@@ -0,0 +1,211 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var shimTmpl = template.Must(template.New("shim").Parse(`package main
+
+import (
+	definitions {{ printf "%q" .ImportPath }}
+
+	"github.com/proemergotech/apimd-generator/generator"
+)
+
+func main() {
+	generator.Main(definitions.{{ .Func }}(){{ if .Options }}, definitions.{{ .Options }}()...{{ end }})
+}
+`))
+
+// shim is a generated main package in a temporary directory, which runs generator.Main with the definitions.
+type shim struct {
+	ImportPath string
+	Func       string
+	Options    string
+
+	dir string
+}
+
+func newShim(pkg string, fn string, options string) (*shim, error) {
+	importPath, err := resolvePackage(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "apimd")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	s := &shim{ImportPath: importPath, Func: fn, Options: options, dir: dir}
+
+	f, err := os.Create(filepath.Join(dir, "main.go"))
+	if err != nil {
+		s.close()
+		return nil, errors.WithStack(err)
+	}
+	err = shimTmpl.Execute(f, s)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		s.close()
+		return nil, errors.WithStack(err)
+	}
+
+	return s, nil
+}
+
+func (s *shim) close() {
+	_ = os.RemoveAll(s.dir)
+}
+
+// build compiles the shim within the module of the working directory, and returns the path of the binary.
+func (s *shim) build() (string, error) {
+	binary := filepath.Join(s.dir, "apimd")
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+
+	cmd := exec.Command("go", "build", "-o", binary, filepath.Join(s.dir, "main.go"))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if err != nil {
+		return "", errors.Wrap(err, "building the definitions")
+	}
+
+	return binary, nil
+}
+
+func (s *shim) run(args []string) error {
+	binary, err := s.build()
+	if err != nil {
+		return err
+	}
+
+	cmd := command(binary, args)
+
+	return errors.WithStack(cmd.Run())
+}
+
+// serve runs the serve command, and restarts it with the rebuilt shim when the watched go files change.
+// While the new version does not build, the old one keeps running.
+func (s *shim) serve(args []string) error {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+
+	var running *exec.Cmd
+	stop := func() {
+		if running != nil {
+			_ = running.Process.Kill()
+			_ = running.Wait()
+			running = nil
+		}
+	}
+	defer stop()
+
+	lastState := ""
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		state, err := s.watchState()
+		if err != nil {
+			return err
+		}
+
+		if state != lastState {
+			lastState = state
+
+			binary, err := s.build()
+			if err == nil {
+				stop()
+				running = command(binary, args)
+				err = running.Start()
+				if err != nil {
+					return errors.WithStack(err)
+				}
+			} else if running == nil {
+				return err
+			}
+		}
+
+		select {
+		case <-interrupt:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchState returns a fingerprint of the go files of the definitions package and its dependencies within the module.
+func (s *shim) watchState() (string, error) {
+	out, err := exec.Command("go", "list", "-deps", "-f", "{{if .Module}}{{if .Module.Main}}{{.Dir}}{{end}}{{end}}", s.ImportPath).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			// keep watching, the build reports the error
+			return string(exitErr.Stderr), nil
+		}
+		return "", errors.WithStack(err)
+	}
+
+	state := make([]string, 0)
+	for _, dir := range strings.Fields(string(out)) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		for _, file := range files {
+			if file.IsDir() || !strings.HasSuffix(file.Name(), ".go") {
+				continue
+			}
+			state = append(state, filepath.Join(dir, file.Name())+" "+strconv.FormatInt(file.ModTime().UnixNano(), 10)+" "+strconv.FormatInt(file.Size(), 10))
+		}
+	}
+	sort.Strings(state)
+
+	return strings.Join(state, "\n"), nil
+}
+
+func command(binary string, args []string) *exec.Cmd {
+	cmd := exec.Command(binary, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd
+}
+
+// resolvePackage returns the import path of pkg, which can also be a directory relative to the working directory.
+func resolvePackage(pkg string) (string, error) {
+	out, err := exec.Command("go", "list", "-f", "{{.Name}} {{.ImportPath}}", pkg).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", errors.Errorf("loading package %v: %s", pkg, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", errors.WithStack(err)
+	}
+
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return "", errors.Errorf("loading package %v: unexpected go list output: %s", pkg, out)
+	}
+	if fields[0] == "main" {
+		return "", errors.Errorf("package %v is a main package, move the definitions to an importable package", pkg)
+	}
+
+	return fields[1], nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tempModule creates a module with a definitions package in a temporary directory, and changes the working
+// directory to it, as cmd/apimd is run inside the module of the service.
+func tempModule(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "apimd-shim")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+		_ = os.RemoveAll(dir)
+	})
+
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/service\n\ngo 1.15\n")
+	writeFile(t, filepath.Join(dir, "apimd", "definitions.go"), "package apimd\n\nconst Name = \"service\"\n")
+	writeFile(t, filepath.Join(dir, "cmd", "main.go"), "package main\n\nfunc main() {}\n")
+
+	err = os.Chdir(dir)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	return dir
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	err = ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+}
+
+func TestNewShim(t *testing.T) {
+	tempModule(t)
+
+	s, err := newShim("./apimd", "Definitions", "Options")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer s.close()
+
+	if s.ImportPath != "example.com/service/apimd" {
+		t.Errorf("import path: %v", s.ImportPath)
+	}
+
+	src, err := ioutil.ReadFile(filepath.Join(s.dir, "main.go"))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "main.go", src, 0)
+	if err != nil {
+		t.Fatalf("invalid shim source: %+v\n%s", err, src)
+	}
+	imports := make([]string, 0)
+	for _, imp := range f.Imports {
+		imports = append(imports, imp.Path.Value)
+	}
+	if strings.Join(imports, " ") != `"example.com/service/apimd" "github.com/proemergotech/apimd-generator/generator"` {
+		t.Errorf("imports: %v", imports)
+	}
+	if !strings.Contains(string(src), "generator.Main(definitions.Definitions(), definitions.Options()...)") {
+		t.Errorf("shim should call generator.Main with the definitions and the options:\n%s", src)
+	}
+
+	s2, err := newShim("example.com/service/apimd", "Definitions", "")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer s2.close()
+	src, _ = ioutil.ReadFile(filepath.Join(s2.dir, "main.go"))
+	if !strings.Contains(string(src), "generator.Main(definitions.Definitions())\n") {
+		t.Errorf("shim should call generator.Main without options:\n%s", src)
+	}
+
+	_, err = newShim("./cmd", "Definitions", "")
+	if err == nil || !strings.Contains(err.Error(), "is a main package") {
+		t.Errorf("want error for main package, got: %v", err)
+	}
+	_, err = newShim("./missing", "Definitions", "")
+	if err == nil || !strings.Contains(err.Error(), "loading package ./missing") {
+		t.Errorf("want error for missing package, got: %v", err)
+	}
+}
+
+func TestWatchState(t *testing.T) {
+	dir := tempModule(t)
+	s := &shim{ImportPath: "example.com/service/apimd"}
+
+	state := func() string {
+		result, err := s.watchState()
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		return result
+	}
+
+	initial := state()
+	if !strings.Contains(initial, filepath.Join(dir, "apimd", "definitions.go")) {
+		t.Fatalf("state should list the go files of the package, got: %v", initial)
+	}
+	if state() != initial {
+		t.Errorf("state should not change without changes")
+	}
+
+	writeFile(t, filepath.Join(dir, "apimd", "README.md"), "docs")
+	if state() != initial {
+		t.Errorf("state should not change for other files")
+	}
+
+	writeFile(t, filepath.Join(dir, "apimd", "definitions.go"), "package apimd\n\nconst Name = \"renamed service\"\n")
+	changed := state()
+	if changed == initial {
+		t.Errorf("state should change when a go file changes")
+	}
+
+	writeFile(t, filepath.Join(dir, "apimd", "types.go"), "package apimd\n\ntype User struct{}\n")
+	added := state()
+	if added == changed {
+		t.Errorf("state should change when a go file is added")
+	}
+
+	// dependencies within the module are watched too
+	writeFile(t, filepath.Join(dir, "types", "types.go"), "package types\n\ntype ID string\n")
+	writeFile(t, filepath.Join(dir, "apimd", "types.go"), "package apimd\n\nimport \"example.com/service/types\"\n\ntype User struct{ ID types.ID }\n")
+	withDep := state()
+	if !strings.Contains(withDep, filepath.Join(dir, "types", "types.go")) {
+		t.Errorf("state should list the go files of the dependencies, got: %v", withDep)
+	}
+
+	// broken packages are still watched, the build reports the error
+	writeFile(t, filepath.Join(dir, "apimd", "types.go"), "package apimd\n\nimport \"example.com/service/missing\"\n")
+	if broken := state(); broken == withDep {
+		t.Errorf("state should change for a broken package")
+	}
+}
@@ -0,0 +1,3 @@
+package generator
+
+const htmlTmpl = "{{- define \"page\" -}}\n<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n<title>{{ .Title }}</title>\n<style>\nbody { margin: 0; font-family: -apple-system, \"Segoe UI\", Helvetica, Arial, sans-serif; font-size: 14px; color: #222; }\nnav { position: fixed; top: 0; bottom: 0; left: 0; width: 300px; overflow-y: auto; background: #f6f7f9; border-right: 1px solid #ddd; padding: 12px; box-sizing: border-box; }\nnav input { width: 100%; padding: 6px; box-sizing: border-box; margin-bottom: 8px; }\nnav ul { list-style: none; margin: 0; padding-left: 12px; }\nnav > ul { padding-left: 0; }\nnav a { color: #222; text-decoration: none; display: block; padding: 2px 0; white-space: nowrap; overflow: hidden; text-overflow: ellipsis; }\nnav a:hover { text-decoration: underline; }\nnav .category { font-weight: bold; margin-top: 8px; }\nnav .title { font-size: 16px; font-weight: bold; margin-bottom: 8px; }\nmain { margin-left: 300px; padding: 12px 32px; max-width: 1000px; }\nsection.route { border-top: 1px solid #eee; padding: 8px 0; }\n.method { display: inline-block; min-width: 60px; padding: 2px 6px; border-radius: 3px; color: #fff; background: #777; font-size: 12px; font-weight: bold; text-align: center; }\n.method-CONSUMED { background: #9b51e0; } .method-FIRED { background: #219653; }\n.method-GET { background: #2f80ed; } .method-POST { background: #27ae60; } .method-PUT, .method-PATCH { background: #f2994a; } .method-DELETE { background: #eb5757; }\n.path { font-family: monospace; font-size: 14px; }\n.type { color: #777; }\n.desc { color: #555; }\ncode { background: #f3f3f3; padding: 0 3px; }\npre { background: #f3f3f3; padding: 8px; overflow-x: auto; }\ndetails { margin-left: 4px; }\ndetails > ul, details > ol { margin: 2px 0; padding-left: 20px; border-left: 1px dotted #ccc; }\nsummary { cursor: pointer; }\ntable { border-collapse: collapse; }\ntd, th { text-align: left; padding: 2px 12px 2px 0; vertical-align: top; }\n.hidden { display: none; }\n</style>\n</head>\n<body>\n<nav>\n<a class=\"title\" href=\"{{ indexHref }}\">{{ .Doc.Name }}</a>\n<input id=\"search\" type=\"search\" placeholder=\"Search\" autocomplete=\"off\">\n<ul id=\"results\" class=\"hidden\"></ul>\n<ul id=\"toc\">\n{{- range .Doc.Categories }}\n{{-     if .Groups }}\n<li class=\"category\">{{ .Name }}\n<ul>\n{{-         range .Groups }}\n{{-             $group := . }}\n<li><a href=\"{{ groupHref . }}\">{{ .Name }}</a>\n<ul>\n{{-             range .Routes }}\n<li data-search=\"{{ searchText $group . }}\"><a href=\"{{ routeHref $group . }}\"><span class=\"method method-{{ .Method }}\">{{ .Method }}</span> {{ .Name }}</a></li>\n{{-             end }}\n{{-             range .Events }}\n<li data-search=\"{{ eventSearchText $group . }}\"><a href=\"{{ eventHref $group . }}\">{{ template \"direction\" . }} {{ .Name }}</a></li>\n{{-             end }}\n</ul>\n</li>\n{{-         end }}\n</ul>\n</li>\n{{-     end }}\n{{- end }}\n{{- if .Doc.DataStructures }}\n<li class=\"category\"><a href=\"{{ dataStructuresHref }}\">Data Structures</a></li>\n{{- end }}\n</ul>\n</nav>\n<main>\n{{- if .Index }}\n<h1>{{ .Doc.Name }}</h1>\n{{-     range .Doc.Categories }}\n{{-         if .Groups }}\n<h2>{{ .Name }}</h2>\n{{-             range .Groups }}\n{{-                 $group := . }}\n<h3><a href=\"{{ groupHref . }}\">{{ .Name }}</a></h3>\n<table>\n{{-                 range .Routes }}\n<tr><td><span class=\"method method-{{ .Method }}\">{{ .Method }}</span></td><td><a class=\"path\" href=\"{{ routeHref $group . }}\">{{ $group.Prefix }}{{ .Path }}</a></td><td>{{ .Name }}</td></tr>\n{{-                 end }}\n{{-                 range .Events }}\n<tr><td>{{ template \"direction\" . }}</td><td><a class=\"path\" href=\"{{ eventHref $group . }}\">{{ .EventName }}</a></td><td>{{ .Name }}</td></tr>\n{{-                 end }}\n</table>\n{{-             end }}\n{{-         end }}\n{{-     end }}\n{{- else if .Heading }}\n<h1>{{ .Heading }}</h1>\n{{- end }}\n{{- range .Categories }}\n{{-     if .Groups }}\n<h2>{{ .Name }}</h2>\n{{-         range .Groups }}\n{{-             $group := . }}\n<h3 id=\"{{ groupAnchor . }}\">{{ .Name }}{{ if not .Events }} <span class=\"path\">{{ if .Prefix }}{{ .Prefix }}{{ else }}/{{ end }}</span>{{ end }}</h3>\n{{-             range .Routes }}\n{{-                 $route := . }}\n<section class=\"route\" id=\"{{ routeAnchor $group . }}\" data-search=\"{{ searchText $group . }}\">\n<h4><span class=\"method method-{{ .Method }}\">{{ .Method }}</span> <span class=\"path\">{{ $group.Prefix }}{{ .Path }}</span> {{ .Name }}</h4>\n{{-                 range .Description }}\n<p>{{ . }}</p>\n{{-                 end }}\n{{-                 range .Snippets }}\n<pre><code class=\"language-{{ .Lang }}\">{{ .Code }}</code></pre>\n{{-                 end }}\n{{-                 if or .Params .Query }}\n<h5>Parameters</h5>\n<table>\n{{-                     range $key, $value := .Query }}\n<tr><td><code>{{ $key }}</code></td><td>{{ template \"value\" $value }}</td></tr>\n{{-                     end }}\n{{-                     range $key, $value := .Params }}\n<tr><td><code>{{ $key }}</code></td><td>{{ template \"value\" $value }}</td></tr>\n{{-                     end }}\n</table>\n{{-                 end }}\n{{-                 if .RequestBody }}\n<h5>Request</h5>\n{{ template \"tree\" .RequestBody }}\n{{- template \"example\" dict \"Body\" .RequestBody \"Example\" .RequestExample }}\n{{-                 end }}\n{{-                 range $statusCode, $responseBody := .ResponseBodies }}\n<h5>Response {{ $statusCode }}</h5>\n{{-                     if $responseBody }}\n{{ template \"tree\" $responseBody }}\n{{- template \"example\" dict \"Body\" $responseBody \"Example\" (index $route.ResponseExamples $statusCode) }}\n{{-                     end }}\n{{-                 end }}\n</section>\n{{-             end }}\n{{-             range .Events }}\n<section class=\"route\" id=\"{{ eventAnchor . }}\" data-search=\"{{ eventSearchText $group . }}\">\n<h4>{{ template \"direction\" . }} <span class=\"path\">{{ .EventName }}</span> {{ .Name }}</h4>\n<p>{{ eventLabel . }}</p>\n{{-                 range .Description }}\n<p>{{ . }}</p>\n{{-                 end }}\n{{-                 if .Headers }}\n<h5>Headers</h5>\n<table>\n{{-                     range $key, $value := .Headers }}\n<tr><td><code>{{ $key }}</code></td><td>{{ template \"value\" $value }}</td></tr>\n{{-                     end }}\n</table>\n{{-                 end }}\n{{-                 if .Payload }}\n<h5>Payload</h5>\n{{ template \"tree\" .Payload }}\n{{- template \"example\" dict \"Body\" .Payload \"Example\" .PayloadExample }}\n{{-                 end }}\n</section>\n{{-             end }}\n{{-         end }}\n{{-     end }}\n{{- end }}\n{{- if .DataStructures }}\n<h2 id=\"data-structures\">Data Structures</h2>\n{{-     range .DataStructures }}\n<section class=\"route\" id=\"{{ dataStructureAnchor .Name }}\" data-search=\"{{ .Name }}\">\n<h4>{{ .Name }}</h4>\n{{ template \"tree\" .Value }}\n</section>\n{{-     end }}\n{{- end }}\n</main>\n{{- if .SearchIndex }}\n<script src=\"{{ .SearchIndex }}\"></script>\n{{- end }}\n<script>\n(function () {\n  var search = document.getElementById(\"search\");\n  var toc = document.getElementById(\"toc\");\n  var results = document.getElementById(\"results\");\n  search.addEventListener(\"input\", function () {\n    var terms = search.value.toLowerCase().split(/\\s+/).filter(function (t) { return t; });\n    var matches = function (text) {\n      text = text.toLowerCase();\n      return terms.every(function (t) { return text.indexOf(t) >= 0; });\n    };\n\n    if (window.apimdSearchIndex) {\n      // site: search the routes of all pages in the index, instead of the current page\n      toc.classList.toggle(\"hidden\", terms.length > 0);\n      results.classList.toggle(\"hidden\", terms.length === 0);\n      results.innerHTML = \"\";\n      window.apimdSearchIndex.filter(function (entry) { return terms.length > 0 && matches(entry.text); }).forEach(function (entry) {\n        var a = document.createElement(\"a\");\n        a.href = entry.url;\n        a.textContent = entry.method + \" \" + entry.path + \" \" + entry.name;\n        var li = document.createElement(\"li\");\n        li.appendChild(a);\n        results.appendChild(li);\n      });\n      return;\n    }\n\n    document.querySelectorAll(\"[data-search]\").forEach(function (el) {\n      el.classList.toggle(\"hidden\", !matches(el.getAttribute(\"data-search\")));\n    });\n  });\n{{- if .LiveReload }}\n  var version = {{ .Version }};\n  setInterval(function () {\n    fetch(\"{{ .VersionPath }}\").then(function (resp) {\n      return resp.ok ? resp.text() : version;\n    }).then(function (v) {\n      if (v !== version) {\n        location.reload();\n      }\n    }).catch(function () {});\n  }, 1000);\n{{- end }}\n})();\n</script>\n</body>\n</html>\n{{ end }}\n\n{{ define \"tree\" }}\n{{-     if isValue . }}\n{{-         template \"value\" . }}\n{{-     else if isRef . }}\n<a href=\"{{ dataStructureHref .Name }}\">{{ .Name }}</a>\n{{-     else if isArray . }}\n<details open><summary><span class=\"type\">{{ arrayType . }}</span>{{ with arrayConstraints . }} <span class=\"desc\">{{ . }}</span>{{ end }}</summary>\n<ul><li>{{ template \"tree\" .Item }}</li></ul>\n</details>\n{{-     else if isOneOf . }}\n<details open><summary><span class=\"type\">one of</span>{{ with .Discriminator }} <span class=\"desc\">discriminator: <code>{{ . }}</code></span>{{ end }}</summary>\n<ol>\n{{-         range .Variants }}\n<li>{{ template \"tree\" . }}</li>\n{{-         end }}\n</ol>\n</details>\n{{-     else }}\n<details open><summary><span class=\"type\">object</span></summary>\n<ul>\n{{-         range $key, $value := . }}\n<li><code>{{ $key }}</code> {{ template \"tree\" $value }}</li>\n{{-         end }}\n</ul>\n</details>\n{{-     end }}\n{{- end }}\n\n{{ define \"example\" }}\n{{-     if not (isValue .Body) }}\n<details><summary>Example</summary><pre><code class=\"language-json\">{{ json .Example }}</code></pre></details>\n{{-     end }}\n{{- end }}\n\n{{ define \"direction\" -}}\n<span class=\"method method-{{ upper .Direction }}\">{{ upper .Direction }}</span>\n{{- end }}\n\n{{ define \"value\" -}}\n{{ if .Null }}<code>null</code>{{ else }}<code>{{ .Value }}</code>{{ end }} <span class=\"type\">({{ if .Enum }}enum[{{ .APIMDType }}]{{ else }}{{ .APIMDType }}{{ end }}{{ if .Opt }}, optional{{end}}{{ if .Nullable }}, nullable{{ end }})</span>{{ if .Enum }} <span class=\"enum\">one of: {{ range $i, $member := .Enum }}{{ if $i }}, {{ end }}<code>{{ $member }}</code>{{ end }}</span>{{ end }}{{ if .Desc }} <span class=\"desc\">{{ .Desc }}</span>{{ end }}\n{{- end }}\n"
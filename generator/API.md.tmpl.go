@@ -1,3 +1,3 @@
 package generator
 
-const apimdTmpl = "{{- define \"base\" -}}\nFORMAT: 1A\n\n# {{ .Name }}\n\nGENERATED, DO NOT EDIT, to regenerate:\n{{-     range .Usage }}\n- {{ . }}\n{{-     end }}\n\n{{-     range .Categories }}\n{{-         if .Groups }}\n\n## Group {{ .Name }}\n{{-             range .Groups }}\n\n### {{ .Name }} [{{ if .Prefix }}{{ .Prefix }}{{ else }}/{{ end }}]\n{{-                 $prefix := .Prefix }}\n{{-                 range .Routes }}\n\n#### {{ .Name }} [{{ .Method }} {{ $prefix }}{{ .Path }}]\n{{-                     if .Description }}\n{{-                         range .Description }}\n{{ . }}\n{{-                         end }}\n{{-                     end }}\n{{-                     if or .Params .Query }}\n\n+ Parameters\n{{-                         range $key, $value := .Query }}\n    + `{{ $key }}`: `{{ $value.Value }}` {{ template \"meta\" $value }}\n{{-                        end }}\n{{-                         range $key, $value := .Params }}\n    + `{{ $key }}`: `{{ $value.Value }}` {{ template \"meta\" $value }}\n{{-                         end }}\n{{-                     end }}\n{{-                     if .RequestBody }}\n\n+ Request\n{{-                         if isValue .RequestBody }}\n\n        {{ .RequestBody.Value }}\n{{-                         else }}\n    + Attributes\n{{- template \"attributes\" dict \"Value\" .RequestBody \"Indent\" 8 }}\n{{-                         end }}\n{{-                     end }}\n{{-                     if .ResponseBodies }}\n{{-                         range $statusCode, $responseBody := .ResponseBodies }}\n\n+ Response {{ dig3 $statusCode }}\n{{-                             if $responseBody }}\n{{-                                 if isValue $responseBody }}\n\n        {{ $responseBody.Value }}\n{{-                                 else }}\n    + Attributes\n{{- template \"attributes\" dict \"Value\" $responseBody \"Indent\" 8 }}\n{{-                                 end }}\n{{-                             end }}\n{{-                         end }}\n{{-                     end }}\n{{-                 end }}\n{{-             end }}\n{{-         end }}\n{{-     end }}\n{{ end }}\n\n{{ define \"attributes\" }}\n{{-     $indent := .Indent }}\n{{-     $parentArray := isArray .Value }}\n{{-     range $key, $value := .Value }}\n{{-         if isValue $value }}\n{{-             if $parentArray }}\n{{ indent $indent }}+ `{{ $value.Value }}` {{ template \"meta\" $value }}\n{{-             else }}\n{{ indent $indent }}+ `{{ $key }}`: `{{ $value.Value }}` {{ template \"meta\" $value }}\n{{-             end }}\n{{-         else }}\n{{-             if $parentArray }}\n{{ indent $indent }}+ (object)\n{{-             else }}\n{{ indent $indent }}+ `{{ $key }}` {{- if isArray $value }}(array){{ end }}\n{{-             end }}\n{{- template \"attributes\" dict \"Value\" $value \"Indent\" (add $indent 4) }}\n{{-         end }}\n{{-     end}}\n{{- end }}\n\n{{ define \"meta\" -}}\n({{ .APIMDType }}{{ if .Opt }}, optional{{end}}){{ if .Desc }} - {{ .Desc }}{{ end }}\n{{- end }}\n"
+const apimdTmpl = "{{- define \"base\" -}}\nFORMAT: 1A\n\n# {{ .Name }}\n\nGENERATED, DO NOT EDIT, to regenerate:\n{{-     range .Usage }}\n- {{ . }}\n{{-     end }}\n\n{{-     range .Categories }}\n{{-         if .Groups }}\n\n## Group {{ .Name }}\n{{-             range .Groups }}\n{{-                 if .Events }}\n\n### {{ .Name }}\n{{-                     range .Events }}\n\n#### {{ .Name }}\n\n{{ eventLabel . }}: `{{ .EventName }}`\n{{-                         if .Description }}\n{{                              range .Description }}\n{{ . }}\n{{-                             end }}\n{{-                         end }}\n{{-                         if .Headers }}\n\n+ Headers\n{{-                             range $key, $value := .Headers }}\n    + `{{ $key }}`{{ template \"example\" $value }} {{ template \"meta\" $value }}\n{{- template \"members\" dict \"Value\" $value \"Indent\" 8 }}\n{{-                             end }}\n{{-                         end }}\n{{-                         if .Payload }}\n\n+ Payload\n{{- template \"body\" .Payload }}\n{{- template \"example body\" dict \"Body\" .Payload \"Example\" .PayloadExample }}\n{{-                         end }}\n{{-                     end }}\n{{-                 else }}\n\n### {{ .Name }} [{{ if .Prefix }}{{ .Prefix }}{{ else }}/{{ end }}]\n{{-                     $prefix := .Prefix }}\n{{-                     range .Routes }}\n{{-                         $route := . }}\n\n#### {{ .Name }} [{{ .Method }} {{ $prefix }}{{ .Path }}]\n{{-                         if .Description }}\n{{-                             range .Description }}\n{{ . }}\n{{-                             end }}\n{{-                         end }}\n{{-                         range .Snippets }}\n\n```{{ .Lang }}\n{{ .Code }}\n```\n{{-                         end }}\n{{-                         if or .Params .Query }}\n\n+ Parameters\n{{-                             range $key, $value := .Query }}\n    + `{{ $key }}`{{ template \"example\" $value }} {{ template \"meta\" $value }}\n{{- template \"members\" dict \"Value\" $value \"Indent\" 8 }}\n{{-                            end }}\n{{-                             range $key, $value := .Params }}\n    + `{{ $key }}`{{ template \"example\" $value }} {{ template \"meta\" $value }}\n{{- template \"members\" dict \"Value\" $value \"Indent\" 8 }}\n{{-                             end }}\n{{-                         end }}\n{{-                         if .RequestBody }}\n\n+ Request\n{{- template \"body\" .RequestBody }}\n{{- template \"example body\" dict \"Body\" .RequestBody \"Example\" .RequestExample }}\n{{-                         end }}\n{{-                         if .ResponseBodies }}\n{{-                             range $statusCode, $responseBody := .ResponseBodies }}\n\n+ Response {{ dig3 $statusCode }}\n{{-                                 if $responseBody }}\n{{- template \"body\" $responseBody }}\n{{- template \"example body\" dict \"Body\" $responseBody \"Example\" (index $route.ResponseExamples $statusCode) }}\n{{-                                 end }}\n{{-                             end }}\n{{-                         end }}\n{{-                     end }}\n{{-                 end }}\n{{-             end }}\n{{-         end }}\n{{-     end }}\n{{-     if .DataStructures }}\n\n# Data Structures\n{{-         range .DataStructures }}\n\n## {{ .Name }} (object)\n{{- template \"attributes\" dict \"Value\" .Value \"Indent\" 0 }}\n{{-         end }}\n{{-     end }}\n{{ end }}\n\n{{ define \"body\" }}\n{{-     if isValue . }}\n\n        {{ if .Null }}null{{ else }}{{ .Value }}{{ end }}\n{{-     else if isRef . }}\n    + Attributes ({{ .Name }})\n{{-     else if isArray . }}\n    + Attributes {{ template \"array\" dict \"Value\" . \"Indent\" 4 }}\n{{-     else }}\n    + Attributes\n{{-         if isOneOf . }}\n{{-             if .Discriminator }}\n\n        Discriminator: `{{ .Discriminator }}`\n{{              end }}\n        + One Of\n{{-             range .Variants }}\n{{-                 if isValue . }}\n            + {{ if .Null }}null{{ else }}`{{ .Value }}`{{ end }} {{ template \"meta\" . }}\n{{- template \"members\" dict \"Value\" . \"Indent\" 16 }}\n{{-                 else if isRef . }}\n            + Properties\n                + Include {{ .Name }}\n{{-                 else if isArray . }}\n            + {{ template \"array\" dict \"Value\" . \"Indent\" 12 }}\n{{-                 else }}\n            + Properties\n{{- template \"attributes\" dict \"Value\" . \"Indent\" 16 }}\n{{-                 end }}\n{{-             end }}\n{{-         else }}\n{{- template \"attributes\" dict \"Value\" . \"Indent\" 8 }}\n{{-         end }}\n{{-     end }}\n{{- end }}\n\n{{ define \"example body\" }}\n{{-     if not (isValue .Body) }}\n\n    + Body\n\n{{ indentLines (json .Example) 12 }}\n{{-     end }}\n{{- end }}\n\n{{ define \"attributes\" }}\n{{-     $indent := .Indent }}\n{{-     range $key, $value := .Value }}\n{{-         if isValue $value }}\n{{ indent $indent }}+ `{{ $key }}`{{ template \"example\" $value }} {{ template \"meta\" $value }}\n{{- template \"members\" dict \"Value\" $value \"Indent\" (add $indent 4) }}\n{{-         else if isRef $value }}\n{{ indent $indent }}+ `{{ $key }}` ({{ $value.Name }})\n{{-         else if isArray $value }}\n{{ indent $indent }}+ `{{ $key }}` {{ template \"array\" dict \"Value\" $value \"Indent\" $indent }}\n{{-         else }}\n{{ indent $indent }}+ `{{ $key }}`\n{{- template \"attributes\" dict \"Value\" $value \"Indent\" (add $indent 4) }}\n{{-         end }}\n{{-     end}}\n{{- end }}\n\n{{ define \"array\" -}}\n({{ arrayType .Value }}){{ with arrayConstraints .Value }} - {{ . }}{{ end }}\n{{-     $item := .Value.Item }}\n{{-     $indent := add .Indent 4 }}\n{{-     if isValue $item }}\n{{ indent $indent }}+ {{ if $item.Null }}null{{ else }}`{{ $item.Value }}`{{ end }} {{ template \"meta\" $item }}\n{{- template \"members\" dict \"Value\" $item \"Indent\" (add $indent 4) }}\n{{-     else if isArray $item }}\n{{ indent $indent }}+ {{ template \"array\" dict \"Value\" $item \"Indent\" $indent }}\n{{-     else if not (isRef $item) }}\n{{ indent $indent }}+ (object)\n{{- template \"attributes\" dict \"Value\" $item \"Indent\" (add $indent 4) }}\n{{-     end }}\n{{- end }}\n\n{{ define \"example\" -}}\n{{ if not .Null }}: `{{ .Value }}`{{ end }}\n{{- end }}\n\n{{ define \"meta\" -}}\n({{ if .Enum }}enum[{{ .APIMDType }}]{{ else }}{{ .APIMDType }}{{ end }}{{ if .Opt }}, optional{{end}}{{ if .Nullable }}, nullable{{ end }}){{ if .Desc }} - {{ .Desc }}{{ end }}\n{{- end }}\n\n{{ define \"members\" }}\n{{-     if .Value.Enum }}\n{{ indent .Indent }}+ Members\n{{-         $indent := add .Indent 4 }}\n{{-         range .Value.Enum }}\n{{ indent $indent }}+ `{{ . }}`\n{{-         end }}\n{{-     end }}\n{{- end }}\n"
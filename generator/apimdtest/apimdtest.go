@@ -0,0 +1,32 @@
+// Package apimdtest runs the checks of the generator against a service as tests.
+package apimdtest
+
+import (
+	"testing"
+
+	"github.com/proemergotech/apimd-generator/generator"
+)
+
+// TestContract runs generator.CheckContract as a subtest for every route, which fails with the failures of the route.
+func TestContract(t *testing.T, doc *generator.Document, target generator.ContractTarget) {
+	for _, result := range generator.CheckContract(doc, target) {
+		result := result
+		t.Run(result.Method+" "+result.Path, func(t *testing.T) {
+			for _, failure := range result.Failures {
+				t.Error(failure)
+			}
+		})
+	}
+}
+
+// TestRoutes fails t with the routes of lister which are not documented, and the documented routes which are not
+// registered on lister, see: generator.CompareRoutes
+func TestRoutes(t *testing.T, doc *generator.Document, lister generator.RouteLister) {
+	report := generator.CompareRoutes(doc, lister)
+	for _, route := range report.Undocumented {
+		t.Errorf("undocumented route: %v %v", route.Method, route.Path)
+	}
+	for _, route := range report.Missing {
+		t.Errorf("documented route is not registered: %v %v", route.Method, route.Path)
+	}
+}
@@ -0,0 +1,47 @@
+package apimdtest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/proemergotech/apimd-generator/generator"
+)
+
+func testDocument() *generator.Document {
+	return &generator.Document{
+		Name: "Users",
+		Categories: []*generator.DocCategory{{Name: "Http", Groups: []*generator.DocGroup{{
+			Name:   "Users",
+			Prefix: "/api",
+			Routes: []*generator.DocRoute{{
+				Name:   "List",
+				Method: http.MethodGet,
+				Path:   "/users",
+				ResponseBodies: map[int]interface{}{http.StatusOK: &generator.DocArray{Item: map[string]interface{}{
+					"id": &generator.DocValue{Value: "1", APIMDType: "string"},
+				}}},
+			}},
+		}}}},
+	}
+}
+
+func TestTestContract(t *testing.T) {
+	var got string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Method + " " + r.URL.Path
+		_, _ = w.Write([]byte(`[{"id":"2"}]`))
+	})
+
+	TestContract(t, testDocument(), generator.ContractHandler(handler))
+
+	if got != "GET /api/users" {
+		t.Errorf("want the example request of the route, got %v", got)
+	}
+}
+
+func TestTestRoutes(t *testing.T) {
+	mux := generator.NewServeMux()
+	mux.HandleFunc("/api/users", func(w http.ResponseWriter, r *http.Request) {})
+
+	TestRoutes(t, testDocument(), mux)
+}
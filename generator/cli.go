@@ -0,0 +1,565 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Main runs the generator as a command line tool, with the command given as the first argument:
+//
+//	generate    update API.md, this is the default command
+//	check       fail if API.md is not up to date
+//	lint        check the definitions against the LintRules
+//	diff        report the changes between two versions of the document
+//	export      write the document as JSON
+//	convert     convert between API.md and JSON documents
+//	serve       serve the document as HTML
+//	site        write the document as a static HTML site
+//	collection  write the http routes as a Postman or Insomnia collection
+//	client      write a Go client package of the http routes
+//	typescript  write TypeScript declarations of the bodies, and fetch functions of the http routes
+//	schema      write a JSON Schema of every body
+//	mock        serve a mock of the http routes, responding with the examples
+//
+// Use it instead of Generate in apimd/main.go, eg. `go run apimd/main.go lint -rule param-description=off`.
+// Run a command with -h for its flags.
+func Main(d Definitons, options ...Option) {
+	err := NewGenerator(options...).Run(d, os.Args[1:])
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+}
+
+// Run runs the command given by args, see: Main
+// Commands which work on document files only, like convert, can be run without definitions.
+func (g *Generator) Run(d Definitons, args []string) error {
+	command := "generate"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command = args[0]
+		args = args[1:]
+	}
+
+	run, ok := map[string]func(d Definitons, args []string) error{
+		"generate":   g.runGenerate,
+		"check":      g.runCheck,
+		"lint":       g.runLint,
+		"diff":       g.runDiff,
+		"export":     g.runExport,
+		"convert":    g.runConvert,
+		"serve":      g.runServe,
+		"site":       g.runSite,
+		"collection": g.runCollection,
+		"client":     g.runClient,
+		"typescript": g.runTypeScript,
+		"schema":     g.runSchema,
+		"mock":       g.runMock,
+	}[command]
+	if !ok {
+		return errors.Errorf("unknown command: %v", command)
+	}
+
+	return run(d, args)
+}
+
+// collectFlags registers the flags of the options which change the collected document.
+func (g *Generator) collectFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&g.dataStructures, "data-structures", g.dataStructures, "move repeated objects to a Data Structures section")
+	fs.BoolVar(&g.zeroValues, "zero-values", g.zeroValues, "document explicitly set zero values")
+}
+
+func (g *Generator) runGenerate(d Definitons, args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	g.collectFlags(fs)
+	fs.StringVar(&g.jsonExportPath, "json", g.jsonExportPath, "write the document as JSON to this path as well")
+	err := fs.Parse(args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if d == nil {
+		return errors.New("generate requires definitions")
+	}
+
+	g.Generate(d)
+
+	return nil
+}
+
+func (g *Generator) runCheck(d Definitons, args []string) error {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	g.collectFlags(fs)
+	err := fs.Parse(args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if d == nil {
+		return errors.New("check requires definitions")
+	}
+
+	doc, err := g.CollectDocument(d)
+	if err != nil {
+		return err
+	}
+	want, err := RenderAPIMD(doc)
+	if err != nil {
+		return err
+	}
+
+	got, err := ioutil.ReadFile(d.OutputPath())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if !bytes.Equal(want, got) {
+		return errors.Errorf("%v is not up to date, run the generate command", d.OutputPath())
+	}
+
+	fmt.Println(d.OutputPath() + " is up to date")
+
+	return nil
+}
+
+func (g *Generator) runLint(d Definitons, args []string) error {
+	config := make(LintConfig, len(g.lintConfig))
+	for name, severity := range g.lintConfig {
+		config[name] = severity
+	}
+
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	g.collectFlags(fs)
+	fs.Var(lintRuleFlag(config), "rule", "set the severity of a rule: name=off|warn|error, can be repeated")
+	jsonOutput := fs.Bool("json", false, "print the findings as JSON")
+	listRules := fs.Bool("list", false, "list the rules with their default severity")
+	err := fs.Parse(args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if *listRules {
+		for _, rule := range LintRules {
+			fmt.Printf("%-20s %-5s %v\n", rule.Name, rule.Severity, rule.Description)
+		}
+		return nil
+	}
+	if d == nil {
+		return errors.New("lint requires definitions")
+	}
+
+	doc, err := g.CollectDocument(d)
+	if err != nil {
+		return err
+	}
+	findings, err := Lint(doc, config)
+	if err != nil {
+		return err
+	}
+
+	if *jsonOutput {
+		b, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		fmt.Println(string(b))
+	} else {
+		for _, finding := range findings {
+			fmt.Println(finding)
+		}
+	}
+
+	errorCount := 0
+	for _, finding := range findings {
+		if finding.Severity == LintError {
+			errorCount++
+		}
+	}
+	if errorCount > 0 {
+		return errors.Errorf("lint failed with %v errors", errorCount)
+	}
+
+	return nil
+}
+
+func (g *Generator) runDiff(d Definitons, args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: diff [flags] old [new]\n\nold and new are API.md or JSON document files, new defaults to the current definitions.")
+		fs.PrintDefaults()
+	}
+	g.collectFlags(fs)
+	jsonOutput := fs.Bool("json", false, "print the report as JSON")
+	allowBreaking := fs.Bool("allow-breaking", false, "do not fail on breaking changes")
+	err := fs.Parse(args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		return errors.New("diff requires one or two document files")
+	}
+
+	oldDoc, err := ReadDocument(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var newDoc *Document
+	if fs.NArg() == 2 {
+		newDoc, err = ReadDocument(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+	} else {
+		if d == nil {
+			return errors.New("diff against the current version requires definitions")
+		}
+		newDoc, err = g.CollectDocument(d)
+		if err != nil {
+			return err
+		}
+	}
+
+	report := Diff(oldDoc, newDoc)
+	if *jsonOutput {
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		fmt.Println(string(b))
+	} else {
+		fmt.Print(report.Summary())
+	}
+
+	if report.Breaking && !*allowBreaking {
+		return errors.New("breaking changes found")
+	}
+
+	return nil
+}
+
+func (g *Generator) runExport(d Definitons, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	g.collectFlags(fs)
+	output := fs.String("o", "", "output file, defaults to stdout")
+	err := fs.Parse(args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if d == nil {
+		return errors.New("export requires definitions")
+	}
+
+	doc, err := g.CollectDocument(d)
+	if err != nil {
+		return err
+	}
+	b, err := MarshalDocument(doc)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*output, b)
+}
+
+func (g *Generator) runConvert(_ Definitons, args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: convert [flags] input\n\ninput is an API.md or JSON document file.")
+		fs.PrintDefaults()
+	}
+	to := fs.String("to", "", "output format: apimd or json, defaults to the other format than the input")
+	output := fs.String("o", "", "output file, defaults to stdout")
+	err := fs.Parse(args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("convert requires an input file")
+	}
+
+	data, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	doc, err := parseDocument(data)
+	if err != nil {
+		return errors.Wrapf(err, "reading %v", fs.Arg(0))
+	}
+
+	format := *to
+	if format == "" {
+		format = "json"
+		if isJSONDocument(data) {
+			format = "apimd"
+		}
+	}
+
+	var b []byte
+	switch format {
+	case "json":
+		b, err = MarshalDocument(doc)
+	case "apimd":
+		b, err = RenderAPIMD(doc)
+	default:
+		return errors.Errorf("unknown format: %v", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*output, b)
+}
+
+func (g *Generator) runServe(d Definitons, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	g.collectFlags(fs)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	err := fs.Parse(args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if d == nil {
+		return errors.New("serve requires definitions")
+	}
+
+	doc, err := g.CollectDocument(d)
+	if err != nil {
+		return err
+	}
+	handler, err := newPreviewHandler(doc)
+	if err != nil {
+		return err
+	}
+
+	log.Print("Serving " + d.Name() + " on http://" + *addr)
+
+	return errors.WithStack(http.ListenAndServe(*addr, handler))
+}
+
+func (g *Generator) runMock(d Definitons, args []string) error {
+	fs := flag.NewFlagSet("mock", flag.ContinueOnError)
+	g.collectFlags(fs)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	err := fs.Parse(args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if d == nil {
+		return errors.New("mock requires definitions")
+	}
+
+	doc, err := g.CollectDocument(d)
+	if err != nil {
+		return err
+	}
+
+	log.Print("Serving a mock of " + d.Name() + " on http://" + *addr)
+
+	return errors.WithStack(http.ListenAndServe(*addr, NewMockHandler(doc)))
+}
+
+func (g *Generator) runSite(d Definitons, args []string) error {
+	fs := flag.NewFlagSet("site", flag.ContinueOnError)
+	g.collectFlags(fs)
+	output := fs.String("o", "./apimd-site", "output directory")
+	err := fs.Parse(args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if d == nil {
+		return errors.New("site requires definitions")
+	}
+
+	dir, err := filepath.Abs(*output)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	doc, err := g.CollectDocument(d)
+	if err != nil {
+		return err
+	}
+	err = WriteHTMLSite(doc, dir)
+	if err != nil {
+		return err
+	}
+
+	log.Print("Updated " + filepath.Join(dir, siteIndexFile) + ":1")
+
+	return nil
+}
+
+func (g *Generator) runCollection(d Definitons, args []string) error {
+	fs := flag.NewFlagSet("collection", flag.ContinueOnError)
+	g.collectFlags(fs)
+	format := fs.String("format", "postman", "collection format: postman or insomnia")
+	baseURL := fs.String("base-url", "http://localhost:8080", "base URL of the requests, a variable of the collection")
+	output := fs.String("o", "", "output file, defaults to stdout")
+	err := fs.Parse(args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if d == nil {
+		return errors.New("collection requires definitions")
+	}
+
+	doc, err := g.CollectDocument(d)
+	if err != nil {
+		return err
+	}
+
+	var b []byte
+	switch *format {
+	case "postman":
+		b, err = MarshalPostman(doc, *baseURL)
+	case "insomnia":
+		b, err = MarshalInsomnia(doc, *baseURL)
+	default:
+		return errors.Errorf("unknown format: %v", *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*output, b)
+}
+
+func (g *Generator) runClient(d Definitons, args []string) error {
+	fs := flag.NewFlagSet("client", flag.ContinueOnError)
+	pkgName := fs.String("package", "client", "package name of the client")
+	output := fs.String("o", "", "output file, defaults to stdout")
+	err := fs.Parse(args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if d == nil {
+		return errors.New("client requires definitions")
+	}
+
+	b, err := GenerateClient(d, *pkgName)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*output, b)
+}
+
+func (g *Generator) runTypeScript(d Definitons, args []string) error {
+	fs := flag.NewFlagSet("typescript", flag.ContinueOnError)
+	g.collectFlags(fs)
+	fetch := fs.Bool("fetch", false, "add a typed fetch function for every http route")
+	output := fs.String("o", "", "output file, defaults to stdout")
+	err := fs.Parse(args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if d == nil {
+		return errors.New("typescript requires definitions")
+	}
+
+	doc, err := g.CollectDocument(d)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*output, RenderTypeScript(doc, *fetch))
+}
+
+func (g *Generator) runSchema(d Definitons, args []string) error {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	g.collectFlags(fs)
+	dir := fs.String("o", "./apimd-schemas", "output directory")
+	err := fs.Parse(args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if d == nil {
+		return errors.New("schema requires definitions")
+	}
+
+	doc, err := g.CollectDocument(d)
+	if err != nil {
+		return err
+	}
+	err = WriteJSONSchemas(doc, *dir)
+	if err != nil {
+		return err
+	}
+
+	log.Print("Updated " + *dir)
+
+	return nil
+}
+
+// ReadDocument reads a document from an API.md or a JSON export file, see: ParseAPIMD, UnmarshalDocument
+func ReadDocument(path string) (*Document, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	doc, err := parseDocument(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %v", path)
+	}
+
+	return doc, nil
+}
+
+func parseDocument(data []byte) (*Document, error) {
+	if isJSONDocument(data) {
+		return UnmarshalDocument(data)
+	}
+
+	return ParseAPIMD(data)
+}
+
+func isJSONDocument(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("{"))
+}
+
+func writeOutput(path string, b []byte) error {
+	if path == "" {
+		_, err := os.Stdout.Write(b)
+		return errors.WithStack(err)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = ioutil.WriteFile(abs, b, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	log.Print("Updated " + abs + ":1")
+
+	return nil
+}
+
+type lintRuleFlag LintConfig
+
+func (f lintRuleFlag) String() string {
+	return ""
+}
+
+func (f lintRuleFlag) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return errors.Errorf("invalid rule: %v, expected: name=severity", value)
+	}
+	f[parts[0]] = parts[1]
+
+	return nil
+}
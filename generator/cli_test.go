@@ -0,0 +1,220 @@
+package generator
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fileDefinitions are test definitions with their API.md in a temporary directory.
+type fileDefinitions struct {
+	testDefinitions
+	outputPath string
+}
+
+func (d *fileDefinitions) OutputPath() string {
+	return d.outputPath
+}
+
+func newFileDefinitions(t *testing.T) (*fileDefinitions, string) {
+	dir, err := ioutil.TempDir("", "apimd")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	type user struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	d := &fileDefinitions{outputPath: filepath.Join(dir, "API.md")}
+	d.groups = func(f *Factory) []Group {
+		return []Group{&HTTPGroup{
+			Name: "Users",
+			Routes: []*HTTPRoute{
+				{
+					Name:   "Get",
+					Method: http.MethodGet,
+					Path:   "/users/:id",
+					Request: struct {
+						ID string `param:"id"`
+					}{ID: f.Param("1").String()},
+					Responses: map[int]interface{}{http.StatusOK: user{ID: f.Body("1").String(), Name: f.Body("John").String()}},
+				},
+				{
+					Name:      "List",
+					Method:    http.MethodGet,
+					Path:      "/users",
+					Responses: map[int]interface{}{http.StatusOK: user{ID: f.Body("2").String(), Name: f.Body("Jane").String()}},
+				},
+			},
+		}}
+	}
+
+	return d, dir
+}
+
+func TestRunCommand(t *testing.T) {
+	d, _ := newFileDefinitions(t)
+
+	err := NewGenerator().Run(d, []string{"publish"})
+	if err == nil || !strings.Contains(err.Error(), "unknown command: publish") {
+		t.Errorf("want unknown command error, got: %v", err)
+	}
+
+	err = NewGenerator().Run(d, []string{"generate", "-unknown"})
+	if err == nil {
+		t.Errorf("want error for unknown flag")
+	}
+
+	err = NewGenerator().Run(nil, []string{"export"})
+	if err == nil || !strings.Contains(err.Error(), "export requires definitions") {
+		t.Errorf("want missing definitions error, got: %v", err)
+	}
+
+	// flags without a command run generate
+	err = NewGenerator().Run(d, []string{"-data-structures"})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if _, err := os.Stat(d.OutputPath()); err != nil {
+		t.Errorf("want generated API.md, got: %v", err)
+	}
+}
+
+func TestRunCheck(t *testing.T) {
+	d, _ := newFileDefinitions(t)
+
+	err := NewGenerator().Run(d, []string{"check"})
+	if err == nil {
+		t.Errorf("want error for missing API.md")
+	}
+
+	err = NewGenerator().Run(d, []string{"generate"})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	err = NewGenerator().Run(d, []string{"check"})
+	if err != nil {
+		t.Errorf("want up to date API.md, got: %+v", err)
+	}
+
+	// the flags change the collected document
+	err = NewGenerator().Run(d, []string{"check", "-data-structures"})
+	if err == nil || !strings.Contains(err.Error(), "is not up to date") {
+		t.Errorf("want not up to date error with data structures, got: %v", err)
+	}
+
+	err = ioutil.WriteFile(d.OutputPath(), []byte("# Edited\n"), 0644)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	err = NewGenerator().Run(d, []string{"check"})
+	if err == nil || !strings.Contains(err.Error(), "is not up to date") {
+		t.Errorf("want not up to date error, got: %v", err)
+	}
+}
+
+func TestRunExportAndConvert(t *testing.T) {
+	d, dir := newFileDefinitions(t)
+	jsonPath := filepath.Join(dir, "API.json")
+	apimdPath := filepath.Join(dir, "converted.md")
+
+	err := NewGenerator().Run(d, []string{"generate"})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	err = NewGenerator().Run(d, []string{"export", "-o", jsonPath})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	// convert works with document files only
+	err = NewGenerator().Run(nil, []string{"convert", "-o", apimdPath, jsonPath})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	want, _ := ioutil.ReadFile(d.OutputPath())
+	got, _ := ioutil.ReadFile(apimdPath)
+	if !bytes.Equal(want, got) {
+		t.Errorf("converted JSON export differs from API.md, want:\n%s\ngot:\n%s", want, got)
+	}
+
+	convertedJSON := filepath.Join(dir, "converted.json")
+	err = NewGenerator().Run(nil, []string{"convert", "-to", "json", "-o", convertedJSON, apimdPath})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if _, err := ReadDocument(convertedJSON); err != nil {
+		t.Errorf("want JSON document, got: %+v", err)
+	}
+
+	err = NewGenerator().Run(nil, []string{"convert", "-to", "yaml", jsonPath})
+	if err == nil || !strings.Contains(err.Error(), "unknown format: yaml") {
+		t.Errorf("want unknown format error, got: %v", err)
+	}
+	err = NewGenerator().Run(nil, []string{"convert"})
+	if err == nil {
+		t.Errorf("want error without input file")
+	}
+	err = NewGenerator().Run(nil, []string{"convert", filepath.Join(dir, "missing.md")})
+	if err == nil {
+		t.Errorf("want error for missing input file")
+	}
+}
+
+func TestRunDiff(t *testing.T) {
+	d, dir := newFileDefinitions(t)
+	jsonPath := filepath.Join(dir, "API.json")
+
+	err := NewGenerator().Run(d, []string{"generate", "-json", jsonPath})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	err = NewGenerator().Run(nil, []string{"diff", d.OutputPath(), jsonPath})
+	if err != nil {
+		t.Errorf("want no changes between API.md and its JSON export, got: %+v", err)
+	}
+	err = NewGenerator().Run(d, []string{"diff", "-json", d.OutputPath()})
+	if err != nil {
+		t.Errorf("want no changes against the definitions, got: %+v", err)
+	}
+
+	groups := d.groups
+	d.groups = func(f *Factory) []Group {
+		result := groups(f)
+		group := result[0].(*HTTPGroup)
+		group.Routes = group.Routes[:1]
+		return result
+	}
+
+	err = NewGenerator().Run(d, []string{"diff", d.OutputPath()})
+	if err == nil || !strings.Contains(err.Error(), "breaking changes found") {
+		t.Errorf("want breaking changes error for the removed route, got: %v", err)
+	}
+	err = NewGenerator().Run(d, []string{"diff", "-allow-breaking", d.OutputPath()})
+	if err != nil {
+		t.Errorf("want no error with -allow-breaking, got: %+v", err)
+	}
+
+	err = NewGenerator().Run(nil, []string{"diff", d.OutputPath()})
+	if err == nil || !strings.Contains(err.Error(), "requires definitions") {
+		t.Errorf("want missing definitions error, got: %v", err)
+	}
+	err = NewGenerator().Run(nil, []string{"diff"})
+	if err == nil {
+		t.Errorf("want error without document files")
+	}
+	err = NewGenerator().Run(nil, []string{"diff", "a.md", "b.md", "c.md"})
+	if err == nil {
+		t.Errorf("want error for too many document files")
+	}
+}
@@ -0,0 +1,597 @@
+package generator
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"net/http"
+	pathpkg "path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+var clientTmpl = template.Must(template.New("client").Parse(`// Code generated by apimd-generator. DO NOT EDIT.
+
+// Package {{ .Package }} is a client of the http routes of {{ .Name }}.
+package {{ .Package }}
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+{{- range .Imports }}
+	{{ if .Alias }}{{ .Name }} {{ end }}{{ printf "%q" .Path }}
+{{- end }}
+)
+
+var (
+	// the path params, the query and the body are taken from the fields of the requests tagged with param, query and
+	// json, the same way as they are documented
+	paramJSON = newJSON("param")
+	queryJSON = newJSON("query")
+	bodyJSON  = newJSON("json")
+
+	pathParamRegex = regexp.MustCompile(` + "`{(\\w+)}`" + `)
+)
+
+// HTTPClient sends the requests of a Client, eg. an *http.Client.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type Client struct {
+	baseURL    string
+	httpClient HTTPClient
+}
+
+type Option func(c *Client)
+
+// WithHTTPClient sets the client sending the requests, it is http.DefaultClient by default.
+func WithHTTPClient(httpClient HTTPClient) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// New returns a client sending its requests to baseURL, eg. http://users:8080.
+func New(baseURL string, options ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, o := range options {
+		o(c)
+	}
+
+	return c
+}
+
+// StatusError is returned for the responses with an undocumented status code.
+type StatusError struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected response: %v %v", e.StatusCode, http.StatusText(e.StatusCode))
+}
+{{- range .Methods }}
+{{-     $method := . }}
+{{-     if .Response }}
+
+// {{ .Response }} is the result of {{ .Name }}, with the body of the documented status code set.
+type {{ .Response }} struct {
+	StatusCode int
+	Header     http.Header
+{{-         range .Successes }}
+{{-             if .Type }}
+	{{ .Field }} {{ .Result }}
+{{-             end }}
+{{-         end }}
+}
+{{-     end }}
+{{-     range .Errors }}
+
+// {{ .Name }} is returned by {{ $method.Name }} for the documented {{ .Code }} {{ .Text }} response.
+type {{ .Name }} struct {
+	Header http.Header
+{{-         if .Type }}
+	Body   {{ .Type }}
+{{-         end }}
+}
+
+func (e *{{ .Name }}) Error() string {
+	return {{ printf "%q" (print $method.Name ": " .Code " " .Text) }}
+}
+{{-     end }}
+
+// {{ .Name }} sends {{ .Method }} {{ .Path }}
+{{-     if .Description }}
+//
+{{-         range .Description }}
+// {{ . }}
+{{-         end }}
+{{-     end }}
+func (c *Client) {{ .Name }}(ctx context.Context{{ if .Request }}, req {{ .Request }}{{ end }}) ({{ if .Result }}{{ .Result }}, {{ end }}error) {
+	resp, body, err := c.do(ctx, {{ printf "%q" .Method }}, {{ printf "%q" .Path }}, {{ if .Request }}req{{ else }}nil{{ end }}, {{ .Params }}, {{ .Query }}, {{ .Body }})
+	if err != nil {
+		return {{ .Zero }}err
+	}
+
+	switch resp.StatusCode {
+{{-     range .Successes }}
+	case {{ .Code }}:
+{{-         if .Type }}
+		var result {{ .Type }}
+		err = bodyJSON.Unmarshal(body, &result)
+		if err != nil {
+			return nil, fmt.Errorf("decoding {{ .Code }} response: %w", err)
+		}
+{{-             if $method.Response }}
+		return &{{ $method.Response }}{StatusCode: resp.StatusCode, Header: resp.Header, {{ .Field }}: {{ if .Ptr }}&{{ end }}result}, nil
+{{-             else }}
+		return {{ if .Ptr }}&{{ end }}result, nil
+{{-             end }}
+{{-         else if $method.Response }}
+		return &{{ $method.Response }}{StatusCode: resp.StatusCode, Header: resp.Header}, nil
+{{-         else }}
+		return {{ $method.Zero }}nil
+{{-         end }}
+{{-     end }}
+{{-     range .Errors }}
+	case {{ .Code }}:
+		e := &{{ .Name }}{Header: resp.Header}
+{{-         if .Type }}
+		err = bodyJSON.Unmarshal(body, &e.Body)
+		if err != nil {
+			return {{ $method.Zero }}fmt.Errorf("decoding {{ .Code }} response: %w", err)
+		}
+{{-         end }}
+		return {{ $method.Zero }}e
+{{-     end }}
+	}
+{{-     if not .Successes }}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return {{ .Zero }}nil
+	}
+{{-     end }}
+
+	return {{ .Zero }}&StatusError{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}
+}
+{{- end }}
+
+func (c *Client) do(ctx context.Context, method string, path string, req interface{}, params bool, query bool, body bool) (*http.Response, []byte, error) {
+	if params {
+		values, err := encode(paramJSON, req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("encoding path params: %w", err)
+		}
+		for _, match := range pathParamRegex.FindAllStringSubmatch(path, -1) {
+			value, ok := values[match[1]]
+			if !ok || value == nil {
+				return nil, nil, fmt.Errorf("missing path param: %v", match[1])
+			}
+			path = strings.Replace(path, match[0], url.PathEscape(fmt.Sprint(value)), 1)
+		}
+	}
+
+	u := c.baseURL + path
+	if query {
+		values, err := encode(queryJSON, req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("encoding query: %w", err)
+		}
+		q := url.Values{}
+		for k, v := range values {
+			if items, ok := v.([]interface{}); ok {
+				for _, item := range items {
+					q.Add(k, fmt.Sprint(item))
+				}
+			} else if v != nil {
+				q.Add(k, fmt.Sprint(v))
+			}
+		}
+		if len(q) > 0 {
+			u += "?" + q.Encode()
+		}
+	}
+
+	var reqBody io.Reader
+	if body {
+		b, err := bodyJSON.Marshal(req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("encoding body: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return nil, nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if body {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	return resp, respBody, nil
+}
+
+func encode(api jsoniter.API, v interface{}) (map[string]interface{}, error) {
+	b, err := api.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	err = api.Unmarshal(b, &values)
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func newJSON(tag string) jsoniter.API {
+	return jsoniter.Config{
+		EscapeHTML:      true,
+		SortMapKeys:     true,
+		UseNumber:       true,
+		TagKey:          tag,
+		OnlyTaggedField: true,
+	}.Froze()
+}
+`))
+
+// clientReservedNames are the names used by the generated client, which the imports must not be shadowed by.
+var clientReservedNames = map[string]bool{
+	"bytes": true, "context": true, "fmt": true, "io": true, "ioutil": true, "http": true, "url": true, "regexp": true,
+	"strings": true, "jsoniter": true, "paramJSON": true, "queryJSON": true, "bodyJSON": true, "pathParamRegex": true,
+	"encode": true, "newJSON": true, "c": true, "ctx": true, "req": true, "resp": true, "body": true, "err": true,
+	"result": true, "e": true,
+}
+
+type clientFile struct {
+	Package string
+	Name    string
+	Imports []*clientImport
+	Methods []*clientMethod
+
+	importNames map[string]string
+}
+
+type clientImport struct {
+	Name  string
+	Path  string
+	Alias bool
+}
+
+// clientMethod is the method of an http route. Routes with a single documented success type return it directly,
+// the others return a Response struct with a field for every documented success status.
+type clientMethod struct {
+	Name        string
+	Method      string
+	Path        string
+	Description []string
+	Request     string
+	Params      bool
+	Query       bool
+	Body        bool
+	Result      string
+	Zero        string
+	Response    string
+	Successes   []*clientStatus
+	Errors      []*clientStatus
+}
+
+type clientStatus struct {
+	Code int
+	Text string
+	// Name is the name of the error type
+	Name string
+	// Field is the field of the Response struct
+	Field  string
+	Type   string
+	Result string
+	Ptr    bool
+}
+
+// GenerateClient returns the source of a Go client package for the http routes of d: a Client with a method for
+// every route, taking the request type of the definitions, and decoding the documented responses into their types.
+// The documented non-2xx responses are returned as typed errors, see: errors.As
+func GenerateClient(d Definitons, pkgName string) ([]byte, error) {
+	file := &clientFile{
+		Package:     pkgName,
+		Name:        d.Name(),
+		Imports:     make([]*clientImport, 0),
+		Methods:     make([]*clientMethod, 0),
+		importNames: make(map[string]string),
+	}
+
+	names := make(map[string]bool)
+	for _, group := range d.Groups(newFactory(newCollector())) {
+		if group.GetCategory() != categoryHTTP {
+			continue
+		}
+
+		for _, route := range group.GetRoutes() {
+			name := exportedName(route.Name)
+			if names[name] {
+				name = exportedName(group.GetName() + " " + route.Name)
+			}
+			for i := 2; names[name]; i++ {
+				name = exportedName(group.GetName()+" "+route.Name) + strconv.Itoa(i)
+			}
+			names[name] = true
+
+			method, err := file.method(name, group.GetRoutePrefix(), route)
+			if err != nil {
+				return nil, errors.Wrapf(err, "generating client of route: [%v] %v", route.Method, route.Path)
+			}
+			file.Methods = append(file.Methods, method)
+		}
+	}
+	sort.Slice(file.Imports, func(i, j int) bool {
+		return file.Imports[i].Path < file.Imports[j].Path
+	})
+
+	buf := &bytes.Buffer{}
+	err := clientTmpl.Execute(buf, file)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "formatting client")
+	}
+
+	return b, nil
+}
+
+func (f *clientFile) method(name string, prefix string, route *Route) (*clientMethod, error) {
+	path := normalizePath(prefix + route.Path)
+	// the part after # only tells routes apart in the document
+	if i := strings.Index(path, "#"); i >= 0 {
+		path = path[:i]
+	}
+
+	m := &clientMethod{
+		Name:        name,
+		Method:      route.Method,
+		Path:        path,
+		Description: route.Description,
+		Successes:   make([]*clientStatus, 0),
+		Errors:      make([]*clientStatus, 0),
+	}
+
+	if route.Request != nil {
+		t := reflect.TypeOf(route.Request)
+		if _, ok := route.Request.(*OneOf); ok {
+			t = reflect.TypeOf((*interface{})(nil)).Elem()
+		}
+		var err error
+		m.Request, err = f.typeExpr(t)
+		if err != nil {
+			return nil, errors.Wrap(err, "request")
+		}
+
+		structType := t
+		for structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+		if structType.Kind() == reflect.Struct {
+			m.Params = hasTaggedField(structType, typeParam)
+			m.Query = hasTaggedField(structType, typeQuery)
+			m.Body = hasTaggedField(structType, "json")
+		} else {
+			m.Body = true
+		}
+	}
+
+	successTypes := make(map[string]bool)
+	for _, statusCode := range sortedStatusCodes(route.Responses) {
+		status := &clientStatus{Code: statusCode, Text: http.StatusText(statusCode)}
+		if status.Text == "" {
+			status.Text = "Status " + strconv.Itoa(statusCode)
+		}
+
+		if body := route.Responses[statusCode]; body != nil {
+			t := reflect.TypeOf(body)
+			if _, ok := body.(*OneOf); ok {
+				// the variants are told apart by the caller
+				t = reflect.TypeOf([]byte{})
+				status.Type = f.importName("encoding/json", "json") + ".RawMessage"
+			} else {
+				var err error
+				status.Type, err = f.typeExpr(t)
+				if err != nil {
+					return nil, errors.Wrapf(err, "response %v", statusCode)
+				}
+			}
+			status.Result, status.Ptr = status.Type, false
+			if k := t.Kind(); k != reflect.Ptr && k != reflect.Slice && k != reflect.Map && k != reflect.Interface {
+				status.Result, status.Ptr = "*"+status.Type, true
+			}
+		}
+
+		if statusCode >= 200 && statusCode < 300 {
+			status.Field = exportedName(status.Text)
+			m.Successes = append(m.Successes, status)
+			if status.Type != "" {
+				successTypes[status.Result] = true
+			}
+		} else {
+			status.Name = name + exportedName(status.Text) + "Error"
+			m.Errors = append(m.Errors, status)
+		}
+	}
+
+	switch {
+	case len(successTypes) > 1 || len(successTypes) == 1 && len(m.Successes) > 1:
+		m.Response = name + "Response"
+		m.Result = "*" + m.Response
+	case len(successTypes) == 1:
+		m.Result = m.Successes[0].Result
+	}
+	if m.Result != "" {
+		m.Zero = "nil, "
+	}
+
+	return m, nil
+}
+
+// typeExpr returns the Go expression of t, and adds the packages of the named types to the imports.
+func (f *clientFile) typeExpr(t reflect.Type) (string, error) {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name(), nil
+		}
+		if !ast.IsExported(t.Name()) || t.PkgPath() == "main" {
+			return "", errors.Errorf("type %v can not be used outside of its package", t)
+		}
+
+		return f.importName(t.PkgPath(), strings.SplitN(t.String(), ".", 2)[0]) + "." + t.Name(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem, err := f.typeExpr(t.Elem())
+		return "*" + elem, err
+
+	case reflect.Slice:
+		elem, err := f.typeExpr(t.Elem())
+		return "[]" + elem, err
+
+	case reflect.Array:
+		elem, err := f.typeExpr(t.Elem())
+		return "[" + strconv.Itoa(t.Len()) + "]" + elem, err
+
+	case reflect.Map:
+		key, err := f.typeExpr(t.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := f.typeExpr(t.Elem())
+		return "map[" + key + "]" + elem, err
+
+	case reflect.Interface:
+		if t.NumMethod() > 0 {
+			return "", errors.Errorf("unsupported interface type: %v", t)
+		}
+		return "interface{}", nil
+
+	case reflect.Struct:
+		fields := make([]string, 0, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			typ, err := f.typeExpr(field.Type)
+			if err != nil {
+				return "", err
+			}
+			if !field.Anonymous {
+				typ = field.Name + " " + typ
+			}
+			if field.Tag != "" {
+				typ += " " + strconv.Quote(string(field.Tag))
+			}
+			fields = append(fields, typ)
+		}
+		return "struct{ " + strings.Join(fields, "; ") + " }", nil
+
+	default:
+		return "", errors.Errorf("unsupported type: %v", t)
+	}
+}
+
+// importName adds the import of a package, and returns the name it can be referred to.
+func (f *clientFile) importName(path string, name string) string {
+	if result, ok := f.importNames[path]; ok {
+		return result
+	}
+
+	used := make(map[string]bool, len(f.Imports))
+	for _, imp := range f.Imports {
+		used[imp.Name] = true
+	}
+	result := name
+	for i := 2; used[result] || clientReservedNames[result]; i++ {
+		result = name + strconv.Itoa(i)
+	}
+
+	f.importNames[path] = result
+	f.Imports = append(f.Imports, &clientImport{Name: result, Path: path, Alias: result != pathpkg.Base(path)})
+
+	return result
+}
+
+// hasTaggedField tells whether t has a field which is encoded with tag, see: walkStruct
+func hasTaggedField(t reflect.Type, tag string) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, tagged := fieldKey(field); !tagged && field.Anonymous {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct && hasTaggedField(embedded, tag) {
+				return true
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name, ok := field.Tag.Lookup(tag); ok && strings.Split(name, ",")[0] != "-" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// exportedName returns an exported identifier of a name, eg. "Get user by ID" becomes GetUserByID.
+func exportedName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	result := ""
+	for _, word := range words {
+		result += strings.ToUpper(word[:1]) + word[1:]
+	}
+	if result == "" || !unicode.IsLetter([]rune(result)[0]) {
+		result = "Route" + result
+	}
+
+	return result
+}
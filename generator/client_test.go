@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type ClientUser struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type ClientUpdateRequest struct {
+	ID    string      `param:"id"`
+	Limit int         `query:"limit"`
+	User  *ClientUser `json:"user"`
+}
+
+type ClientError struct {
+	Message string `json:"message"`
+}
+
+func TestGenerateClient(t *testing.T) {
+	definitions := &testDefinitions{groups: func(f *Factory) []Group {
+		return []Group{
+			&HTTPGroup{
+				Name:        "Users",
+				RoutePrefix: "/api",
+				Routes: []*HTTPRoute{
+					{
+						Name:        "Update user",
+						Method:      http.MethodPut,
+						Path:        "/users/:id",
+						Description: []string{"Updates a user."},
+						Request: ClientUpdateRequest{
+							ID:    f.Param("1").String(),
+							Limit: f.Query("10").Int(),
+							User:  &ClientUser{Name: f.Body("John").String()},
+						},
+						Responses: map[int]interface{}{
+							http.StatusOK:         ClientUser{ID: f.Body("1").String()},
+							http.StatusBadRequest: ClientError{Message: f.Body("invalid").String()},
+							http.StatusNotFound:   nil,
+						},
+					},
+					{
+						Name:   "List",
+						Method: http.MethodGet,
+						Path:   "/users",
+						Responses: map[int]interface{}{
+							http.StatusOK:      []ClientUser{{ID: f.Body("1").String()}},
+							http.StatusCreated: &OneOf{Variants: []interface{}{ClientError{}}},
+						},
+					},
+					{Name: "Delete", Method: http.MethodDelete, Path: "/users"},
+				},
+			},
+			&FiredEventsGroup{Name: "Events", Events: []*GEBEvent{{Name: "Created", EventName: "user/created"}}},
+		}
+	}}
+
+	b, err := GenerateClient(definitions, "users")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	src := string(b)
+
+	for _, want := range []string{
+		"package users",
+		"\t\"github.com/proemergotech/apimd-generator/generator\"\n",
+		"\t\"encoding/json\"\n",
+		"// UpdateUser sends PUT /api/users/{id}\n//\n// Updates a user.\n" +
+			"func (c *Client) UpdateUser(ctx context.Context, req generator.ClientUpdateRequest) (*generator.ClientUser, error) {",
+		`c.do(ctx, "PUT", "/api/users/{id}", req, true, true, true)`,
+		"type UpdateUserBadRequestError struct {\n\tHeader http.Header\n\tBody   generator.ClientError\n}",
+		"type UpdateUserNotFoundError struct {\n\tHeader http.Header\n}",
+		"type ListResponse struct {\n\tStatusCode int\n\tHeader     http.Header\n\tOK         []generator.ClientUser\n\tCreated    json.RawMessage\n}",
+		"func (c *Client) List(ctx context.Context) (*ListResponse, error) {",
+		"func (c *Client) Delete(ctx context.Context) error {",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("client should contain:\n%v\ngot:\n%v", want, src)
+		}
+	}
+	if strings.Contains(src, "Created(") {
+		t.Errorf("client should only contain the http routes")
+	}
+	if err := typeCheckClient(b); err != nil {
+		t.Errorf("invalid client: %v\n%v", err, src)
+	}
+	if err := typeCheckClient([]byte(strings.Replace(src, "generator.ClientUser", "generator.ClientAdmin", 1))); err == nil {
+		t.Errorf("want type error for an undefined type")
+	}
+
+	_, err = GenerateClient(&testDefinitions{groups: func(f *Factory) []Group {
+		type unexported struct{}
+		return []Group{&HTTPGroup{Routes: []*HTTPRoute{{Name: "Get", Method: http.MethodGet, Path: "/", Request: unexported{}}}}}
+	}}, "client")
+	if err == nil {
+		t.Errorf("want error for unexported request type")
+	}
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
+
+// typeCheckClient type checks the source of a generated client. The fixture types of this file are not part of the
+// generator package outside of the tests, so the client imports a package type checked from them instead.
+func typeCheckClient(src []byte) error {
+	fset := token.NewFileSet()
+	test, err := parser.ParseFile(fset, "client_test.go", nil, 0)
+	if err != nil {
+		return err
+	}
+	fixture := &ast.File{Name: ast.NewIdent("generator")}
+	for _, decl := range test.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.TYPE &&
+			strings.HasPrefix(gen.Specs[0].(*ast.TypeSpec).Name.Name, "Client") {
+			fixture.Decls = append(fixture.Decls, gen)
+		}
+	}
+
+	source := importer.ForCompiler(fset, "source", nil)
+	generator, err := (&types.Config{Importer: source}).Check("github.com/proemergotech/apimd-generator/generator", fset, []*ast.File{fixture}, nil)
+	if err != nil {
+		return err
+	}
+
+	client, err := parser.ParseFile(fset, "client.go", src, 0)
+	if err != nil {
+		return err
+	}
+	conf := &types.Config{Importer: importerFunc(func(path string) (*types.Package, error) {
+		if path == generator.Path() {
+			return generator, nil
+		}
+		return source.Import(path)
+	})}
+	_, err = conf.Check(client.Name.Name, fset, []*ast.File{client}, nil)
+
+	return err
+}
+
+func TestExportedName(t *testing.T) {
+	for name, want := range map[string]string{
+		"Get user by ID": "GetUserByID",
+		"list-users":     "ListUsers",
+		"2fa":            "Route2fa",
+		"":               "Route",
+	} {
+		if got := exportedName(name); got != want {
+			t.Errorf("%q: want %v got %v", name, want, got)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package generator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+type postmanCollection struct {
+	Info     *postmanInfo       `json:"info"`
+	Item     []*postmanItem     `json:"item"`
+	Variable []*postmanVariable `json:"variable"`
+}
+
+type postmanInfo struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// postmanItem is a folder with Item, or a request with Request and Response.
+type postmanItem struct {
+	Name     string             `json:"name"`
+	Item     []*postmanItem     `json:"item,omitempty"`
+	Request  *postmanRequest    `json:"request,omitempty"`
+	Response []*postmanResponse `json:"response,omitempty"`
+}
+
+type postmanRequest struct {
+	Method      string           `json:"method"`
+	Header      []*postmanHeader `json:"header"`
+	URL         *postmanURL      `json:"url"`
+	Body        *postmanBody     `json:"body,omitempty"`
+	Description string           `json:"description,omitempty"`
+}
+
+type postmanHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type postmanURL struct {
+	Raw      string             `json:"raw"`
+	Host     []string           `json:"host"`
+	Path     []string           `json:"path"`
+	Query    []*postmanVariable `json:"query,omitempty"`
+	Variable []*postmanVariable `json:"variable,omitempty"`
+}
+
+type postmanVariable struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+type postmanBody struct {
+	Mode    string              `json:"mode"`
+	Raw     string              `json:"raw"`
+	Options *postmanBodyOptions `json:"options"`
+}
+
+type postmanBodyOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+type postmanResponse struct {
+	Name            string           `json:"name"`
+	OriginalRequest *postmanRequest  `json:"originalRequest"`
+	Status          string           `json:"status"`
+	Code            int              `json:"code"`
+	Header          []*postmanHeader `json:"header"`
+	Body            string           `json:"body"`
+}
+
+type insomniaExport struct {
+	Type         string              `json:"_type"`
+	ExportFormat int                 `json:"__export_format"`
+	ExportSource string              `json:"__export_source"`
+	Resources    []*insomniaResource `json:"resources"`
+}
+
+// insomniaResource is a workspace, an environment, a request group or a request.
+type insomniaResource struct {
+	ID             string               `json:"_id"`
+	Type           string               `json:"_type"`
+	ParentID       *string              `json:"parentId"`
+	Name           string               `json:"name"`
+	Description    string               `json:"description,omitempty"`
+	Data           map[string]string    `json:"data,omitempty"`
+	Method         string               `json:"method,omitempty"`
+	URL            string               `json:"url,omitempty"`
+	Body           *insomniaBody        `json:"body,omitempty"`
+	Parameters     []*insomniaParameter `json:"parameters,omitempty"`
+	PathParameters []*insomniaParameter `json:"pathParameters,omitempty"`
+	Headers        []*insomniaParameter `json:"headers,omitempty"`
+}
+
+type insomniaBody struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type insomniaParameter struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// MarshalPostman converts the http routes of doc to a Postman Collection v2.1: the groups become folders, the path
+// params variables, and the examples of the request and the responses are included. The base URL is the baseUrl
+// variable of the collection.
+func MarshalPostman(doc *Document, baseURL string) ([]byte, error) {
+	collection := &postmanCollection{
+		Info:     &postmanInfo{Name: doc.Name, Schema: postmanSchema},
+		Item:     make([]*postmanItem, 0),
+		Variable: []*postmanVariable{{Key: "baseUrl", Value: baseURL}},
+	}
+
+	for _, group := range httpGroups(doc) {
+		folder := &postmanItem{Name: group.Name, Item: make([]*postmanItem, 0, len(group.Routes))}
+		for _, route := range group.Routes {
+			item, err := postmanRouteItem(doc, group, route)
+			if err != nil {
+				return nil, errors.Wrapf(err, "converting route: [%v] %v", route.Method, route.Path)
+			}
+			folder.Item = append(folder.Item, item)
+		}
+		collection.Item = append(collection.Item, folder)
+	}
+
+	b, err := json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return b, nil
+}
+
+func postmanRouteItem(doc *Document, group *DocGroup, route *DocRoute) (*postmanItem, error) {
+	path := routePath(group, route, func(name string) string { return ":" + name })
+
+	u := &postmanURL{
+		Host:     []string{"{{baseUrl}}"},
+		Path:     strings.Split(strings.Trim(path, "/"), "/"),
+		Query:    make([]*postmanVariable, 0),
+		Variable: make([]*postmanVariable, 0),
+	}
+	for _, name := range docPathParamNames(group, route) {
+		param := examplePathParam(route, name)
+		u.Variable = append(u.Variable, &postmanVariable{Key: name, Value: param.Value, Description: param.Desc})
+	}
+	query := url.Values{}
+	for _, param := range exampleQuery(route) {
+		u.Query = append(u.Query, &postmanVariable{Key: param.Name, Value: param.Value, Description: param.Desc, Disabled: param.Opt})
+		if !param.Opt {
+			query.Add(param.Name, param.Value)
+		}
+	}
+	u.Raw = "{{baseUrl}}" + path
+	if len(query) > 0 {
+		u.Raw += "?" + query.Encode()
+	}
+
+	request := &postmanRequest{
+		Method:      route.Method,
+		Header:      make([]*postmanHeader, 0),
+		URL:         u,
+		Description: strings.Join(route.Description, "\n"),
+	}
+	if route.RequestBody != nil {
+		body, err := indentJSON(requestExample(doc, route))
+		if err != nil {
+			return nil, errors.Wrap(err, "request")
+		}
+		request.Header = append(request.Header, &postmanHeader{Key: "Content-Type", Value: "application/json"})
+		request.Body = &postmanBody{Mode: "raw", Raw: body, Options: &postmanBodyOptions{}}
+		request.Body.Options.Raw.Language = "json"
+	}
+
+	item := &postmanItem{Name: route.Name, Request: request, Response: make([]*postmanResponse, 0)}
+	for _, statusCode := range sortedStatusCodes(route.ResponseBodies) {
+		response := &postmanResponse{
+			Name:            strconv.Itoa(statusCode) + " " + http.StatusText(statusCode),
+			OriginalRequest: request,
+			Status:          http.StatusText(statusCode),
+			Code:            statusCode,
+			Header:          make([]*postmanHeader, 0),
+		}
+		if body := route.ResponseBodies[statusCode]; body != nil {
+			var err error
+			response.Body, err = indentJSON(responseExample(doc, route, statusCode))
+			if err != nil {
+				return nil, errors.Wrapf(err, "response %v", statusCode)
+			}
+			response.Header = append(response.Header, &postmanHeader{Key: "Content-Type", Value: "application/json"})
+		}
+		item.Response = append(item.Response, response)
+	}
+
+	return item, nil
+}
+
+// MarshalInsomnia converts the http routes of doc to an Insomnia export (format 4), like MarshalPostman. Insomnia
+// has no saved responses, so the response examples are added to the descriptions of the requests.
+func MarshalInsomnia(doc *Document, baseURL string) ([]byte, error) {
+	workspaceID := "wrk_apimd"
+	export := &insomniaExport{
+		Type:         "export",
+		ExportFormat: 4,
+		ExportSource: "apimd-generator",
+		Resources: []*insomniaResource{
+			{ID: workspaceID, Type: "workspace", Name: doc.Name},
+			{ID: "env_apimd", Type: "environment", ParentID: &workspaceID, Name: "Base Environment", Data: map[string]string{"base_url": baseURL}},
+		},
+	}
+
+	for i, group := range httpGroups(doc) {
+		groupID := "fld_apimd_" + strconv.Itoa(i+1)
+		export.Resources = append(export.Resources, &insomniaResource{ID: groupID, Type: "request_group", ParentID: &workspaceID, Name: group.Name})
+
+		for j, route := range group.Routes {
+			resource, err := insomniaRouteResource(doc, group, route)
+			if err != nil {
+				return nil, errors.Wrapf(err, "converting route: [%v] %v", route.Method, route.Path)
+			}
+			resource.ID = "req_apimd_" + strconv.Itoa(i+1) + "_" + strconv.Itoa(j+1)
+			resource.ParentID = &groupID
+			export.Resources = append(export.Resources, resource)
+		}
+	}
+
+	b, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return b, nil
+}
+
+func insomniaRouteResource(doc *Document, group *DocGroup, route *DocRoute) (*insomniaResource, error) {
+	resource := &insomniaResource{
+		Type:           "request",
+		Name:           route.Name,
+		Method:         route.Method,
+		URL:            "{{ _.base_url }}" + routePath(group, route, func(name string) string { return ":" + name }),
+		Parameters:     make([]*insomniaParameter, 0),
+		PathParameters: make([]*insomniaParameter, 0),
+		Headers:        make([]*insomniaParameter, 0),
+	}
+
+	for _, name := range docPathParamNames(group, route) {
+		param := examplePathParam(route, name)
+		resource.PathParameters = append(resource.PathParameters, &insomniaParameter{Name: name, Value: param.Value, Description: param.Desc})
+	}
+	for _, param := range exampleQuery(route) {
+		resource.Parameters = append(resource.Parameters, &insomniaParameter{Name: param.Name, Value: param.Value, Description: param.Desc, Disabled: param.Opt})
+	}
+
+	if route.RequestBody != nil {
+		body, err := indentJSON(requestExample(doc, route))
+		if err != nil {
+			return nil, errors.Wrap(err, "request")
+		}
+		resource.Body = &insomniaBody{MimeType: "application/json", Text: body}
+		resource.Headers = append(resource.Headers, &insomniaParameter{Name: "Content-Type", Value: "application/json"})
+	}
+
+	description := append([]string{}, route.Description...)
+	for _, statusCode := range sortedStatusCodes(route.ResponseBodies) {
+		description = append(description, "", "Response "+strconv.Itoa(statusCode)+" "+http.StatusText(statusCode))
+		if body := route.ResponseBodies[statusCode]; body != nil {
+			example, err := indentJSON(responseExample(doc, route, statusCode))
+			if err != nil {
+				return nil, errors.Wrapf(err, "response %v", statusCode)
+			}
+			description = append(description, "```json", example, "```")
+		}
+	}
+	resource.Description = strings.TrimSpace(strings.Join(description, "\n"))
+
+	return resource, nil
+}
+
+// httpGroups returns the groups of the http routes, the other categories are not requests which could be sent.
+func httpGroups(doc *Document) []*DocGroup {
+	for _, category := range doc.Categories {
+		if category.Name == categoryHTTP {
+			return category.Groups
+		}
+	}
+
+	return []*DocGroup{}
+}
+
+// docPathParamNames returns the names of the placeholders in the full path of a route.
+func docPathParamNames(group *DocGroup, route *DocRoute) []string {
+	names := make([]string, 0)
+	routePath(group, route, func(name string) string {
+		names = append(names, name)
+		return name
+	})
+
+	return names
+}
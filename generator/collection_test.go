@@ -0,0 +1,135 @@
+package generator
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// testCollectionDocument returns a document as the collector produces it, so it can be rendered as API.md too.
+func testCollectionDocument() *Document {
+	return &Document{
+		Name: "Users",
+		Categories: []*DocCategory{
+			{Name: categoryHTTP, Groups: []*DocGroup{{
+				Name:   "Users",
+				Prefix: "/api",
+				Routes: []*DocRoute{{
+					Name:   "Update",
+					Method: http.MethodPut,
+					Path:   "/users/{id}{?tags,limit}",
+					Params: map[string]*DocValue{
+						"id":    {Value: "1", APIMDType: "string", Desc: "user id"},
+						"tags":  {Value: "a,b", APIMDType: "array"},
+						"limit": {Value: "10", APIMDType: "number", Opt: true},
+					},
+					RequestBody: map[string]interface{}{
+						"user":  &DocRef{Name: "User"},
+						"admin": &DocValue{Value: "true", APIMDType: "boolean"},
+					},
+					ResponseBodies: map[int]interface{}{
+						http.StatusOK:       &DocArray{Item: &DocRef{Name: "User"}},
+						http.StatusNotFound: nil,
+					},
+				}},
+			}}},
+			{Name: categoryFiredEvents, Groups: []*DocGroup{{
+				Name:   "Events",
+				Routes: []*DocRoute{},
+				Events: []*DocEvent{{Name: "Created", Direction: EventFired, EventName: "/user/created"}},
+			}}},
+		},
+		DataStructures: []*DocDataStructure{{Name: "User", Value: map[string]interface{}{
+			"age":        &DocValue{Value: "30", APIMDType: "number"},
+			"deleted_at": &DocValue{Null: true, Nullable: true, APIMDType: "string"},
+		}}},
+	}
+}
+
+func TestMarshalPostman(t *testing.T) {
+	b, err := MarshalPostman(testCollectionDocument(), "http://localhost")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	collection := &postmanCollection{}
+	err = json.Unmarshal(b, collection)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if len(collection.Item) != 1 || collection.Item[0].Name != "Users" || len(collection.Item[0].Item) != 1 {
+		t.Fatalf("want a single Users folder with the http route, got: %s", b)
+	}
+	item := collection.Item[0].Item[0]
+
+	url := item.Request.URL
+	if url.Raw != "{{baseUrl}}/api/users/:id?tags=a&tags=b" {
+		t.Errorf("raw url: %v", url.Raw)
+	}
+	if !reflect.DeepEqual(url.Path, []string{"api", "users", ":id"}) {
+		t.Errorf("path: %v", url.Path)
+	}
+	if !reflect.DeepEqual(url.Variable, []*postmanVariable{{Key: "id", Value: "1", Description: "user id"}}) {
+		t.Errorf("variables: %s", mustJSON(url.Variable))
+	}
+	wantQuery := []*postmanVariable{
+		{Key: "limit", Value: "10", Disabled: true},
+		{Key: "tags", Value: "a"},
+		{Key: "tags", Value: "b"},
+	}
+	if !reflect.DeepEqual(url.Query, wantQuery) {
+		t.Errorf("query: %s", mustJSON(url.Query))
+	}
+	if item.Request.Body == nil || item.Request.Body.Raw == "" {
+		t.Errorf("missing request body")
+	}
+
+	if len(item.Response) != 2 || item.Response[0].Code != http.StatusOK || item.Response[1].Code != http.StatusNotFound {
+		t.Fatalf("responses: %s", mustJSON(item.Response))
+	}
+	if item.Response[1].Body != "" {
+		t.Errorf("empty response should have no body, got %v", item.Response[1].Body)
+	}
+}
+
+func TestMarshalInsomnia(t *testing.T) {
+	b, err := MarshalInsomnia(testCollectionDocument(), "http://localhost")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	export := &insomniaExport{}
+	err = json.Unmarshal(b, export)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	types := make([]string, 0)
+	var request *insomniaResource
+	for _, resource := range export.Resources {
+		types = append(types, resource.Type)
+		if resource.Type == "request" {
+			request = resource
+		}
+	}
+	if !reflect.DeepEqual(types, []string{"workspace", "environment", "request_group", "request"}) {
+		t.Fatalf("resources: %v", types)
+	}
+
+	if request.URL != "{{ _.base_url }}/api/users/:id" {
+		t.Errorf("url: %v", request.URL)
+	}
+	if !reflect.DeepEqual(request.PathParameters, []*insomniaParameter{{Name: "id", Value: "1", Description: "user id"}}) {
+		t.Errorf("path parameters: %s", mustJSON(request.PathParameters))
+	}
+	if len(request.Parameters) != 3 || request.Body == nil {
+		t.Errorf("query parameters and body: %s", mustJSON(request))
+	}
+}
+
+func mustJSON(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -13,16 +13,20 @@ import (
 )
 
 const (
-	typeBody  = "body"
-	typeParam = "param"
-	typeQuery = "query"
+	typeBody   = "body"
+	typeParam  = "param"
+	typeQuery  = "query"
+	typeHeader = "header"
 )
 
 var urlRegex = regexp.MustCompile(`:(\w+)`)
 
 type Collector struct {
-	values  map[int]*Value
-	markers map[string]*Marker
+	values         map[int]*Value
+	markers        map[string]*Marker
+	dataStructures bool
+	zeroValues     bool
+	warnings       []string
 }
 
 func newCollector() *Collector {
@@ -38,15 +42,22 @@ type markedRouteKey struct {
 	routeIndex int
 }
 
-func (c *Collector) collect(d Definitons) *Document {
+func (c *Collector) collect(d Definitons) (*Document, error) {
 	factory := newFactory(c)
 	groups := d.Groups(factory)
 
 	markedRoutes := make(map[markedRouteKey]*Route, len(c.markers))
+	markedEvents := make(map[markedRouteKey]*Event, len(c.markers))
 	for markerK := range c.markers {
 		factory.markerKey = markerK
 		mg := d.Groups(factory)
 		for groupI, group := range mg {
+			if eventGroup, ok := group.(EventGroup); ok {
+				for eventI, event := range eventGroup.GetEvents() {
+					markedEvents[markedRouteKey{groupIndex: groupI, markerKey: markerK, routeIndex: eventI}] = event
+				}
+				continue
+			}
 			for routeI, route := range group.GetRoutes() {
 				markedRoutes[markedRouteKey{groupIndex: groupI, markerKey: markerK, routeIndex: routeI}] = route
 			}
@@ -59,7 +70,22 @@ func (c *Collector) collect(d Definitons) *Document {
 		Categories: make([]*DocCategory, 0),
 	}
 
+	pathErrors := make([]string, 0)
 	for groupI, group := range groups {
+		if eventGroup, ok := group.(EventGroup); ok {
+			events, err := c.collectEvents(d, groupI, eventGroup, markedEvents)
+			if err != nil {
+				return nil, err
+			}
+			docGroup := &DocGroup{
+				Name:   group.GetName(),
+				Routes: make([]*DocRoute, 0),
+				Events: events,
+			}
+			result.addGroup(group.GetCategory(), docGroup)
+			continue
+		}
+
 		routes := group.GetRoutes()
 
 		docRoutes := make([]*DocRoute, 0, len(routes))
@@ -74,6 +100,7 @@ func (c *Collector) collect(d Definitons) *Document {
 				Path:        path,
 			}
 
+			params := make(map[string]interface{})
 			if route.Request != nil {
 				markedRequests := make(map[string]interface{})
 				for mk, mr := range markedRoutes {
@@ -83,20 +110,20 @@ func (c *Collector) collect(d Definitons) *Document {
 				}
 				valueTree, err := c.createTree(d, route.Request, markedRequests)
 				if err != nil {
-					log.Fatalf("%+v", errors.Wrapf(err, "parsing request: [%v] %v", route.Method, route.Path))
+					return nil, errors.Wrapf(err, "parsing request: [%v] %v", route.Method, route.Path)
 				}
 
-				params := c.toMap(c.docValues(valueTree, typeParam))
+				params = c.toMap(c.docValues(valueTree, typeParam, ""))
 				for k, p := range params {
 					pVal, ok := p.(*DocValue)
 					if !ok {
-						log.Fatalf("%+v", errors.New("nested object supplied as param for route: "+route.Name))
+						return nil, errors.New("nested object supplied as param for route: " + route.Name)
 					}
 
 					docRoute.Params[k] = pVal
 				}
 
-				query := c.toMap(c.docValues(valueTree, typeQuery))
+				query := c.toMap(c.docValues(valueTree, typeQuery, ""))
 				queryKeys := make([]string, 0)
 				for k, q := range query {
 					qVal, ok := q.(*DocValue)
@@ -114,7 +141,7 @@ func (c *Collector) collect(d Definitons) *Document {
 							}
 						}
 						if qVal == nil {
-							log.Fatalf("%+v", errors.New("invalid nested object supplied as query for route: "+route.Name))
+							return nil, errors.New("invalid nested object supplied as query for route: " + route.Name)
 						}
 					}
 
@@ -129,7 +156,12 @@ func (c *Collector) collect(d Definitons) *Document {
 
 				docRoute.Path = path
 
-				docRoute.RequestBody = c.docValues(valueTree, typeBody)
+				docRoute.RequestBody = c.docValues(valueTree, typeBody, "")
+				c.logWarnings(fmt.Sprintf("[%v] %v", route.Method, route.Path))
+			}
+
+			for _, pathError := range checkPathParams(group.GetRoutePrefix()+route.Path, params) {
+				pathErrors = append(pathErrors, fmt.Sprintf("[%v] %v: %v", route.Method, route.Path, pathError))
 			}
 
 			docRoute.ResponseBodies = make(map[int]interface{})
@@ -142,10 +174,11 @@ func (c *Collector) collect(d Definitons) *Document {
 				}
 				valueTree, err := c.createTree(d, resp, markedResponses)
 				if err != nil {
-					log.Fatalf("%+v", errors.Wrapf(err, "parsing response: [%v] %v", route.Method, route.Path))
+					return nil, errors.Wrapf(err, "parsing response: [%v] %v", route.Method, route.Path)
 				}
 
-				docRoute.ResponseBodies[statusCode] = c.docValues(valueTree, typeBody)
+				docRoute.ResponseBodies[statusCode] = c.docValues(valueTree, typeBody, "")
+				c.logWarnings(fmt.Sprintf("[%v] %v", route.Method, route.Path))
 			}
 
 			docRoutes = append(docRoutes, docRoute)
@@ -156,25 +189,97 @@ func (c *Collector) collect(d Definitons) *Document {
 			Prefix: group.GetRoutePrefix(),
 			Routes: docRoutes,
 		}
+		result.addGroup(group.GetCategory(), docGroup)
+	}
+
+	if len(pathErrors) > 0 {
+		return nil, errors.New("path params do not match the params of the request:\n" + strings.Join(pathErrors, "\n"))
+	}
+
+	if c.dataStructures {
+		hoistDataStructures(result)
+	}
+	forEachBody(result, untypedDocTree)
+	addExamples(result)
 
-		var category *DocCategory
-		for _, cat := range result.Categories {
-			if cat.Name == group.GetCategory() {
-				category = cat
-				break
+	return result, nil
+}
+
+// collectEvents documents the events of a group, the headers and the payload of an event are collected like the
+// params and the body of a request.
+func (c *Collector) collectEvents(d Definitons, groupI int, group EventGroup, markedEvents map[markedRouteKey]*Event) ([]*DocEvent, error) {
+	events := group.GetEvents()
+
+	docEvents := make([]*DocEvent, 0, len(events))
+	for eventI, event := range events {
+		docEvent := &DocEvent{
+			Name:        event.Name,
+			Direction:   event.Direction,
+			EventName:   event.EventName,
+			Channel:     event.Channel,
+			Description: event.Description,
+			Headers:     make(map[string]*DocValue),
+		}
+		if event.Channel != nil {
+			docEvent.EventName = event.Channel.Namespace + ":" + event.Channel.Name
+		}
+
+		markedHeaders := make(map[string]interface{})
+		markedPayloads := make(map[string]interface{})
+		for mk, me := range markedEvents {
+			if mk.groupIndex == groupI && mk.routeIndex == eventI {
+				markedHeaders[mk.markerKey] = me.Headers
+				markedPayloads[mk.markerKey] = me.Payload
 			}
 		}
-		if category == nil {
-			category = &DocCategory{
-				Name:   group.GetCategory(),
-				Groups: make([]*DocGroup, 0),
+
+		if event.Headers != nil {
+			valueTree, err := c.createTree(d, event.Headers, markedHeaders)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing headers: %v", docEvent.EventName)
+			}
+			for k, h := range c.toMap(c.docValues(valueTree, typeHeader, "")) {
+				hVal, ok := h.(*DocValue)
+				if !ok {
+					return nil, errors.New("nested object supplied as header for event: " + event.Name)
+				}
+				docEvent.Headers[k] = hVal
+			}
+			c.logWarnings(docEvent.EventName)
+		}
+
+		if event.Payload != nil {
+			valueTree, err := c.createTree(d, event.Payload, markedPayloads)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing payload: %v", docEvent.EventName)
 			}
-			result.Categories = append(result.Categories, category)
+			docEvent.Payload = c.docValues(valueTree, typeBody, "")
+			c.logWarnings(docEvent.EventName)
 		}
-		category.Groups = append(category.Groups, docGroup)
+
+		docEvents = append(docEvents, docEvent)
 	}
 
-	return result
+	return docEvents, nil
+}
+
+// addGroup adds group to the category of the document, the categories are kept in the order of their first group.
+func (doc *Document) addGroup(categoryName string, group *DocGroup) {
+	var category *DocCategory
+	for _, cat := range doc.Categories {
+		if cat.Name == categoryName {
+			category = cat
+			break
+		}
+	}
+	if category == nil {
+		category = &DocCategory{
+			Name:   categoryName,
+			Groups: make([]*DocGroup, 0),
+		}
+		doc.Categories = append(doc.Categories, category)
+	}
+	category.Groups = append(category.Groups, group)
 }
 
 func mergeQueryDocValues(target *DocValue, source *DocValue) {
@@ -193,13 +298,34 @@ func joinNonEmpty(sep string, values ...string) string {
 	return strings.Join(a, sep)
 }
 
+type typedTree struct {
+	name   string
+	fields map[string]interface{}
+}
+
+// typedDocTree is an object of a body made from a go type, the type name is used for naming data structures.
+// Trees are only typed while collecting, see: untypedDocTree
+type typedDocTree struct {
+	name   string
+	fields map[string]interface{}
+}
+
+type oneOfTree struct {
+	discriminator string
+	variants      []interface{}
+}
+
 func (c *Collector) createTree(d Definitons, data interface{}, markedData map[string]interface{}) (interface{}, error) {
+	if oneOf, ok := asOneOf(data); ok {
+		return c.createOneOfTree(d, oneOf, markedData)
+	}
+
 	data2, err := encDec(data)
 	if err != nil {
 		return nil, err
 	}
 
-	valueTree, err := c.createIndexTree("", data2, d)
+	valueTree, err := c.createIndexTree("", data2, newGoInfo(data), d)
 	if err != nil {
 		return nil, err
 	}
@@ -218,7 +344,49 @@ func (c *Collector) createTree(d Definitons, data interface{}, markedData map[st
 	return valueTree, nil
 }
 
-func (c *Collector) createIndexTree(key string, data interface{}, defs Definitons) (interface{}, error) {
+func (c *Collector) createOneOfTree(d Definitons, oneOf *OneOf, markedData map[string]interface{}) (interface{}, error) {
+	result := &oneOfTree{
+		discriminator: oneOf.Discriminator,
+		variants:      make([]interface{}, 0, len(oneOf.Variants)),
+	}
+	for i, variant := range oneOf.Variants {
+		markedVariants := make(map[string]interface{}, len(markedData))
+		for mk, mData := range markedData {
+			if mOneOf, ok := asOneOf(mData); ok && i < len(mOneOf.Variants) {
+				markedVariants[mk] = mOneOf.Variants[i]
+			}
+		}
+
+		valueTree, err := c.createTree(d, variant, markedVariants)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing variant %v", i)
+		}
+
+		if oneOf.Discriminator != "" {
+			vt, ok := treeFields(valueTree)
+			if !ok || vt[oneOf.Discriminator] == nil {
+				return nil, errors.Errorf("discriminator %v missing from variant %v", oneOf.Discriminator, i)
+			}
+		}
+
+		result.variants = append(result.variants, valueTree)
+	}
+
+	return result, nil
+}
+
+func asOneOf(data interface{}) (*OneOf, bool) {
+	switch o := data.(type) {
+	case *OneOf:
+		return o, o != nil
+	case OneOf:
+		return &o, true
+	default:
+		return nil, false
+	}
+}
+
+func (c *Collector) createIndexTree(key string, data interface{}, info *goInfo, defs Definitons) (interface{}, error) {
 	if data == nil {
 		return nil, nil
 	}
@@ -227,7 +395,7 @@ func (c *Collector) createIndexTree(key string, data interface{}, defs Definiton
 	case map[string]interface{}:
 		result := make(map[string]interface{}, len(d))
 		for k, v := range d {
-			val, err := c.createIndexTree(key+"."+k, v, defs)
+			val, err := c.createIndexTree(key+"."+k, v, info, defs)
 			if err != nil {
 				return nil, err
 			}
@@ -235,12 +403,15 @@ func (c *Collector) createIndexTree(key string, data interface{}, defs Definiton
 				result[k] = val
 			}
 		}
+		if name, ok := info.typeNames[key]; ok {
+			return &typedTree{name: name, fields: result}, nil
+		}
 		return result, nil
 
 	case []interface{}:
 		result := make([]interface{}, 0, len(d))
 		for k, v := range d {
-			val, err := c.createIndexTree(key+"."+strconv.Itoa(k), v, defs)
+			val, err := c.createIndexTree(key+"."+strconv.Itoa(k), v, info, defs)
 			if err != nil {
 				return nil, err
 			}
@@ -273,7 +444,7 @@ func (c *Collector) createIndexTree(key string, data interface{}, defs Definiton
 			return val, nil
 		}
 
-		if isZero(d) {
+		if isZero(d) && !(c.zeroValues && info.explicit[key]) {
 			// ignore default values, to be able to omit struct fields from documentation
 			return nil, nil
 		}
@@ -290,9 +461,10 @@ func (c *Collector) extendTreeWithMarker(valueTree interface{}, markedTree inter
 
 	switch mt := markedTree.(type) {
 	case map[string]interface{}:
-		vt, ok := valueTree.(map[string]interface{})
+		vt, ok := treeFields(valueTree)
 		if !ok {
 			vt = make(map[string]interface{})
+			valueTree = vt
 		}
 		for k, v := range mt {
 			vt[k], err = c.extendTreeWithMarker(vt[k], v, marker)
@@ -301,7 +473,7 @@ func (c *Collector) extendTreeWithMarker(valueTree interface{}, markedTree inter
 			}
 		}
 
-		return vt, nil
+		return valueTree, nil
 
 	case []interface{}:
 		vt, ok := valueTree.([]interface{})
@@ -333,7 +505,7 @@ func (c *Collector) extendTreeWithMarker(valueTree interface{}, markedTree inter
 	}
 }
 
-func (c *Collector) docValues(v interface{}, typ string) interface{} {
+func (c *Collector) docValues(v interface{}, typ string, path string) interface{} {
 	if v == nil {
 		return nil
 	}
@@ -342,7 +514,7 @@ func (c *Collector) docValues(v interface{}, typ string) interface{} {
 	case map[string]interface{}:
 		result := make(map[string]interface{}, len(vt))
 		for k, v := range vt {
-			val := c.docValues(v, typ)
+			val := c.docValues(v, typ, path+"."+k)
 			if val != nil {
 				result[k] = val
 			}
@@ -353,10 +525,18 @@ func (c *Collector) docValues(v interface{}, typ string) interface{} {
 
 		return result
 
+	case *typedTree:
+		result := c.docValues(vt.fields, typ, path)
+		if m, ok := result.(map[string]interface{}); ok && typ == typeBody {
+			return &typedDocTree{name: vt.name, fields: m}
+		}
+
+		return result
+
 	case []interface{}:
 		result := make([]interface{}, 0, len(vt))
-		for _, v := range vt {
-			val := c.docValues(v, typ)
+		for i, v := range vt {
+			val := c.docValues(v, typ, path+"."+strconv.Itoa(i))
 			if val != nil {
 				result = append(result, val)
 			}
@@ -364,6 +544,43 @@ func (c *Collector) docValues(v interface{}, typ string) interface{} {
 		if len(result) == 0 {
 			return nil
 		}
+		if typ != typeBody {
+			return result
+		}
+
+		return c.docArray(vt, result, path)
+
+	case *oneOfTree:
+		if typ != typeBody {
+			// params and query values of the variants are documented together
+			result := make(map[string]interface{})
+			for _, variant := range vt.variants {
+				for k, v := range c.toMap(c.docValues(variant, typ, path)) {
+					if _, ok := result[k]; !ok {
+						result[k] = v
+					}
+				}
+			}
+			if len(result) == 0 {
+				return nil
+			}
+
+			return result
+		}
+
+		result := &DocOneOf{
+			Discriminator: vt.discriminator,
+			Variants:      make([]interface{}, 0, len(vt.variants)),
+		}
+		for _, variant := range vt.variants {
+			val := c.docValues(variant, typ, path)
+			if val != nil {
+				result.Variants = append(result.Variants, val)
+			}
+		}
+		if len(result.Variants) == 0 {
+			return nil
+		}
 
 		return result
 
@@ -380,6 +597,65 @@ func (c *Collector) docValues(v interface{}, typ string) interface{} {
 	}
 }
 
+// docArray summarizes the elements of an array body with the first one.
+// The constraints of the array are taken from the values of the elements, see: Value.MinItems
+func (c *Collector) docArray(valueTree []interface{}, items []interface{}, path string) *DocArray {
+	result := &DocArray{
+		Item: items[0],
+	}
+
+	shape := signature(items[0], false)
+	for _, item := range items[1:] {
+		if signature(item, false) != shape {
+			c.warnings = append(c.warnings, fmt.Sprintf("elements of array %v have different shapes, only the first one is documented", displayPath(path)))
+			break
+		}
+	}
+
+	for _, item := range valueTree {
+		values := make([]interface{}, 0)
+		if fields, ok := treeFields(item); ok {
+			for _, field := range fields {
+				values = append(values, field)
+			}
+		} else {
+			values = append(values, item)
+		}
+
+		for _, value := range values {
+			v, ok := value.(*Value)
+			if !ok {
+				continue
+			}
+			if result.MinItems == 0 {
+				result.MinItems = v.minItems
+			}
+			if result.MaxItems == 0 {
+				result.MaxItems = v.maxItems
+			}
+			result.UniqueItems = result.UniqueItems || v.uniqueItems
+		}
+	}
+
+	return result
+}
+
+// logWarnings logs the warnings of the last collected tree, subject is the route or event it belongs to.
+func (c *Collector) logWarnings(subject string) {
+	for _, warning := range c.warnings {
+		log.Printf("warning: %v: %v", subject, warning)
+	}
+	c.warnings = nil
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "body"
+	}
+
+	return "`" + strings.TrimPrefix(path, ".") + "`"
+}
+
 func (*Collector) toMap(v interface{}) map[string]interface{} {
 	if v == nil {
 		return map[string]interface{}{}
@@ -404,16 +680,96 @@ func (*Collector) apimdType(jsonVal interface{}) (string, error) {
 	}
 }
 
+func treeFields(valueTree interface{}) (map[string]interface{}, bool) {
+	switch vt := valueTree.(type) {
+	case map[string]interface{}:
+		return vt, true
+	case *typedTree:
+		return vt.fields, true
+	default:
+		return nil, false
+	}
+}
+
+// untypedDocTree returns the document tree v without the type names of its objects.
+func untypedDocTree(v interface{}) interface{} {
+	switch vt := v.(type) {
+	case *typedDocTree:
+		return untypedDocTree(vt.fields)
+
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(vt))
+		for k, v := range vt {
+			result[k] = untypedDocTree(v)
+		}
+		return result
+
+	case *DocArray:
+		result := *vt
+		result.Item = untypedDocTree(vt.Item)
+		return &result
+
+	case *DocOneOf:
+		result := &DocOneOf{
+			Discriminator: vt.Discriminator,
+			Variants:      make([]interface{}, 0, len(vt.Variants)),
+		}
+		for _, variant := range vt.Variants {
+			result.Variants = append(result.Variants, untypedDocTree(variant))
+		}
+		return result
+
+	default:
+		return v
+	}
+}
+
 func isZero(x interface{}) bool {
 	return reflect.DeepEqual(x, reflect.Zero(reflect.TypeOf(x)).Interface())
 }
 
+// checkPathParams returns the mismatches between the placeholders of path and the params.
+func checkPathParams(path string, params map[string]interface{}) []string {
+	result := make([]string, 0)
+
+	placeholders := pathParams(path)
+	inPath := make(map[string]bool, len(placeholders))
+	for _, placeholder := range placeholders {
+		inPath[placeholder] = true
+		if _, ok := params[placeholder]; !ok {
+			result = append(result, "placeholder {"+placeholder+"} has no matching param")
+		}
+	}
+
+	for _, k := range sortedKeys(params) {
+		if !inPath[k] {
+			result = append(result, "param "+k+" is not a placeholder of the path")
+		}
+	}
+
+	return result
+}
+
+// escapedColonPlaceholder replaces the escaped colons of the paths while matching the placeholders.
+const escapedColonPlaceholder = "{colon-esc-placeholr}"
+
+// pathParams returns the names of the placeholders of path, escaped colons are not placeholders, see: normalizePath
+func pathParams(path string) []string {
+	path = strings.Replace(path, "\\:", escapedColonPlaceholder, -1)
+
+	result := make([]string, 0)
+	for _, match := range urlRegex.FindAllStringSubmatch(path, -1) {
+		result = append(result, match[1])
+	}
+
+	return result
+}
+
 // normalizePath see: TestNormalizePath
 func normalizePath(path string) string {
-	const placeholder = "{colon-esc-placeholr}"
-	path = strings.Replace(path, "\\:", placeholder, -1)
+	path = strings.Replace(path, "\\:", escapedColonPlaceholder, -1)
 	path = urlRegex.ReplaceAllString(path, "{$1}")
-	path = strings.Replace(path, placeholder, ":", -1)
+	path = strings.Replace(path, escapedColonPlaceholder, ":", -1)
 
 	return path
 }
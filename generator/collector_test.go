@@ -1,6 +1,12 @@
 package generator
 
 import (
+	"bytes"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -36,3 +42,323 @@ func TestNormalizePath(t *testing.T) {
 		}
 	}
 }
+
+type testDefinitions struct {
+	groups func(f *Factory) []Group
+}
+
+func (*testDefinitions) Name() string {
+	return "Test"
+}
+
+func (*testDefinitions) OutputPath() string {
+	return "./API.md"
+}
+
+func (*testDefinitions) Usage() []string {
+	return nil
+}
+
+func (d *testDefinitions) Groups(f *Factory) []Group {
+	return d.groups(f)
+}
+
+func (*testDefinitions) ParseIndex(index interface{}) (int, error) {
+	switch ind := index.(type) {
+	case float64:
+		return int(ind), nil
+	case string:
+		i, err := strconv.Atoi(ind)
+		if err != nil {
+			return 0, nil
+		}
+		return i, nil
+	default:
+		return 0, nil
+	}
+}
+
+func mustCollect(t *testing.T, c *Collector, d Definitons) *Document {
+	doc, err := c.collect(d)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	return doc
+}
+
+func TestCollectOneOf(t *testing.T) {
+	type card struct {
+		Type   string `json:"type"`
+		Number string `json:"number"`
+	}
+	type bank struct {
+		Type string `json:"type"`
+		IBAN string `json:"iban"`
+	}
+
+	doc := mustCollect(t, newCollector(), &testDefinitions{groups: func(f *Factory) []Group {
+		return []Group{&HTTPGroup{
+			Name: "Payments",
+			Routes: []*HTTPRoute{{
+				Name:   "Pay",
+				Method: http.MethodPost,
+				Path:   "/pay",
+				Request: &OneOf{
+					Discriminator: "type",
+					Variants: []interface{}{
+						card{Type: f.Body("card").String(), Number: f.Body("4111").String()},
+						bank{Type: f.Body("bank").String(), IBAN: f.Body("DE00").String()},
+					},
+				},
+			}},
+		}}
+	}})
+
+	oneOf, ok := doc.Categories[0].Groups[0].Routes[0].RequestBody.(*DocOneOf)
+	if !ok {
+		t.Fatalf("want *DocOneOf request body, got: %T", doc.Categories[0].Groups[0].Routes[0].RequestBody)
+	}
+	if oneOf.Discriminator != "type" || len(oneOf.Variants) != 2 {
+		t.Fatalf("unexpected one of: %+v", oneOf)
+	}
+	for i, want := range []string{"card", "bank"} {
+		variant := oneOf.Variants[i].(map[string]interface{})
+		if got := variant["type"].(*DocValue).Value; got != want {
+			t.Errorf("variant %v: want type `%s` got: `%s`", i, want, got)
+		}
+	}
+}
+
+func TestCollectEvents(t *testing.T) {
+	type headers struct {
+		TraceID string `json:"trace_id"`
+	}
+	type message struct {
+		ID string `json:"id"`
+	}
+
+	doc := mustCollect(t, newCollector(), &testDefinitions{groups: func(f *Factory) []Group {
+		return []Group{
+			&ConsumedMessagesGroup{
+				Name:        "Messages",
+				RoutePrefix: "/msg/users",
+				Events: []*GEBEvent{{
+					Name:        "Deleted",
+					EventName:   "/user/deleted/v1",
+					Description: []string{"Deletes a user."},
+					Headers:     headers{TraceID: f.Header("t1").String()},
+					Body:        message{ID: f.Body("1").String()},
+				}},
+			},
+			&CentrifugeGroup{
+				Name:   "Centrifuge",
+				Events: []*CentrifugeEvent{{Name: "Online", Namespace: "users", Channel: "online"}},
+			},
+		}
+	}})
+
+	group := doc.Categories[0].Groups[0]
+	if doc.Categories[0].Name != categoryConsumedMessages || group.Prefix != "" || len(group.Routes) != 0 {
+		t.Fatalf("unexpected group: %+v", group)
+	}
+	event := group.Events[0]
+	if event.Name != "Deleted" || event.Direction != EventConsumed || event.EventName != "/msg/users/user/deleted/v1" ||
+		event.Channel != nil || len(event.Description) != 1 {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	if traceID := event.Headers["trace_id"]; len(event.Headers) != 1 || traceID.Value != "t1" || traceID.APIMDType != "string" {
+		t.Errorf("unexpected headers: %+v", event.Headers)
+	}
+	if id := event.Payload.(map[string]interface{})["id"].(*DocValue); id.Value != "1" {
+		t.Errorf("unexpected payload: %+v", event.Payload)
+	}
+	if got := mustJSON(event.PayloadExample); got != `{"id":"1"}` {
+		t.Errorf("unexpected payload example: %v", got)
+	}
+
+	event = doc.Categories[1].Groups[0].Events[0]
+	if event.Direction != EventFired || event.EventName != "users:online" || *event.Channel != (DocChannel{Namespace: "users", Name: "online"}) ||
+		event.Payload != nil {
+		t.Errorf("unexpected event: %+v", event)
+	}
+}
+
+func TestCollectNullable(t *testing.T) {
+	type response struct {
+		DeletedAt *string `json:"deleted_at"`
+		Note      string  `json:"note"`
+		Omitted   *string `json:"omitted"`
+	}
+
+	doc := mustCollect(t, newCollector(), &testDefinitions{groups: func(f *Factory) []Group {
+		note := f.Body("note")
+		note.Nullable()
+
+		return []Group{&HTTPGroup{
+			Name: "Notes",
+			Routes: []*HTTPRoute{{
+				Name:   "Get",
+				Method: http.MethodGet,
+				Path:   "/note",
+				Responses: map[int]interface{}{http.StatusOK: response{
+					DeletedAt: f.Null().StringPtr(),
+					Note:      note.String(),
+				}},
+			}},
+		}}
+	}})
+
+	body := doc.Categories[0].Groups[0].Routes[0].ResponseBodies[http.StatusOK].(map[string]interface{})
+	if deletedAt, ok := body["deleted_at"].(*DocValue); !ok || !deletedAt.Null || !deletedAt.Nullable || deletedAt.APIMDType != "string" {
+		t.Errorf("want explicit null string, got: %#v", body["deleted_at"])
+	}
+	if note, ok := body["note"].(*DocValue); !ok || note.Null || !note.Nullable || note.Value != "note" {
+		t.Errorf("want nullable note, got: %#v", body["note"])
+	}
+	if _, ok := body["omitted"]; ok {
+		t.Errorf("want omitted nil pointer, got: %#v", body["omitted"])
+	}
+}
+
+func TestCollectZeroValues(t *testing.T) {
+	type response struct {
+		Count     *int  `json:"count"`
+		Enabled   *bool `json:"enabled"`
+		Untouched int   `json:"untouched"`
+		Scores    []int `json:"scores"`
+	}
+
+	zero := 0
+	disabled := false
+	definitions := &testDefinitions{groups: func(f *Factory) []Group {
+		return []Group{&HTTPGroup{
+			Name: "Counts",
+			Routes: []*HTTPRoute{{
+				Name:   "Get",
+				Method: http.MethodGet,
+				Path:   "/count",
+				Responses: map[int]interface{}{http.StatusOK: response{
+					Count:   &zero,
+					Enabled: &disabled,
+					Scores:  []int{0},
+				}},
+			}},
+		}}
+	}}
+
+	c := newCollector()
+	c.zeroValues = true
+	body := mustCollect(t, c, definitions).Categories[0].Groups[0].Routes[0].ResponseBodies[http.StatusOK].(map[string]interface{})
+	for _, key := range []string{"count", "enabled", "scores"} {
+		if _, ok := body[key]; !ok {
+			t.Errorf("want explicit zero value for %s", key)
+		}
+	}
+	if _, ok := body["untouched"]; ok {
+		t.Errorf("want omitted untouched field, got: %#v", body["untouched"])
+	}
+
+	if body := mustCollect(t, newCollector(), definitions).Categories[0].Groups[0].Routes[0].ResponseBodies[http.StatusOK]; body != nil {
+		t.Errorf("want zero values omitted by default, got: %#v", body)
+	}
+}
+
+func TestCollectArray(t *testing.T) {
+	type item struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	type response struct {
+		Items []item `json:"items"`
+	}
+
+	logs := &bytes.Buffer{}
+	log.SetOutput(logs)
+	defer log.SetOutput(os.Stderr)
+
+	doc := mustCollect(t, newCollector(), &testDefinitions{groups: func(f *Factory) []Group {
+		id := f.Body("1")
+		id.MinItems(1)
+		id.UniqueItems()
+
+		return []Group{&HTTPGroup{
+			Name: "Items",
+			Routes: []*HTTPRoute{{
+				Name:   "List",
+				Method: http.MethodGet,
+				Path:   "/items",
+				Responses: map[int]interface{}{http.StatusOK: response{Items: []item{
+					{ID: id.String(), Name: f.Body("first").String()},
+					{ID: f.Body("2").String()},
+				}}},
+			}},
+		}}
+	}})
+
+	items, ok := doc.Categories[0].Groups[0].Routes[0].ResponseBodies[http.StatusOK].(map[string]interface{})["items"].(*DocArray)
+	if !ok {
+		t.Fatalf("want *DocArray items")
+	}
+	if got := arrayType(items); got != "array[object]" {
+		t.Errorf("want array[object] got: %s", got)
+	}
+	if items.MinItems != 1 || items.MaxItems != 0 || !items.UniqueItems {
+		t.Errorf("unexpected constraints: %+v", items)
+	}
+	if name := items.Item.(map[string]interface{})["name"].(*DocValue).Value; name != "first" {
+		t.Errorf("want first element as representative, got name: %s", name)
+	}
+	if !strings.Contains(logs.String(), "elements of array `items` have different shapes") {
+		t.Errorf("want shape warning, got logs: %s", logs.String())
+	}
+}
+
+func TestCheckPathParams(t *testing.T) {
+	for _, data := range []struct {
+		Path   string
+		Params []string
+		Want   []string
+	}{
+		{
+			Path:   "/users/:user_id/posts/:post_id",
+			Params: []string{"user_id", "post_id"},
+			Want:   []string{},
+		},
+		{
+			Path:   "/users/:user_id",
+			Params: []string{"userId"},
+			Want:   []string{"placeholder {user_id} has no matching param", "param userId is not a placeholder of the path"},
+		},
+		{
+			Path:   "/param/:multiple:param",
+			Params: []string{"multiple", "param"},
+			Want:   []string{},
+		},
+		{
+			Path:   "namespace\\:channel",
+			Params: []string{},
+			Want:   []string{},
+		},
+		{
+			Path:   "/users/:id\\:activate",
+			Params: []string{"id"},
+			Want:   []string{},
+		},
+		{
+			Path:   "/param/:param/path\\:escaped",
+			Params: []string{"param", "escaped"},
+			Want:   []string{"param escaped is not a placeholder of the path"},
+		},
+	} {
+		params := make(map[string]interface{}, len(data.Params))
+		for _, p := range data.Params {
+			params[p] = &DocValue{}
+		}
+
+		got := checkPathParams(data.Path, params)
+		if strings.Join(got, "\n") != strings.Join(data.Want, "\n") {
+			t.Errorf("path: `%s` want: %q got: %q", data.Path, data.Want, got)
+		}
+	}
+}
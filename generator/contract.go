@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// contractHost is the host of the requests sent to a handler, it is replaced with the URL of a server.
+const contractHost = "http://apimd.test"
+
+// ContractTarget sends the example requests of CheckContract, see: ContractHandler, ContractServer
+// Wrap a target to add eg. authorization headers to the requests.
+type ContractTarget func(req *http.Request) (*http.Response, error)
+
+// ContractResult is the outcome of the example request of a route.
+type ContractResult struct {
+	Method string
+	// Path is the documented path of the route
+	Path       string
+	StatusCode int
+	Failures   []string
+}
+
+// ContractHandler sends the requests to handler directly.
+func ContractHandler(handler http.Handler) ContractTarget {
+	return func(req *http.Request) (*http.Response, error) {
+		recorder := httptest.NewRecorder()
+		req.RequestURI = req.URL.RequestURI()
+		handler.ServeHTTP(recorder, req)
+
+		return recorder.Result(), nil
+	}
+}
+
+// ContractServer sends the requests to server with its client.
+func ContractServer(server *httptest.Server) ContractTarget {
+	return func(req *http.Request) (*http.Response, error) {
+		u, err := url.Parse(server.URL)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		req.URL.Scheme = u.Scheme
+		req.URL.Host = u.Host
+		req.Host = u.Host
+
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		return resp, nil
+	}
+}
+
+// CheckContract sends the example request of every http route of doc to target, and checks that the status code
+// of the response is documented, and that its body matches the documented body, see: ValidateBody,
+// apimdtest.TestContract
+func CheckContract(doc *Document, target ContractTarget) []*ContractResult {
+	results := make([]*ContractResult, 0)
+	for _, group := range httpGroups(doc) {
+		for _, route := range group.Routes {
+			result := &ContractResult{Method: route.Method, Path: group.Prefix + route.Path, Failures: make([]string, 0)}
+			err := checkRouteContract(doc, group, route, target, result)
+			if err != nil {
+				result.Failures = append(result.Failures, err.Error())
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+func checkRouteContract(doc *Document, group *DocGroup, route *DocRoute, target ContractTarget, result *ContractResult) error {
+	req, err := exampleRequest(doc, group, route)
+	if err != nil {
+		return err
+	}
+
+	resp, err := target(req)
+	if err != nil {
+		return errors.Wrap(err, "sending request")
+	}
+	defer resp.Body.Close()
+	result.StatusCode = resp.StatusCode
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "reading response")
+	}
+
+	tree, ok := route.ResponseBodies[resp.StatusCode]
+	if !ok {
+		return errors.Errorf("undocumented status code: %v", resp.StatusCode)
+	}
+	if tree == nil {
+		return nil
+	}
+
+	problems, err := ValidateBody(doc, tree, body)
+	if err != nil {
+		return errors.Wrapf(err, "response %v", resp.StatusCode)
+	}
+	for _, problem := range problems {
+		result.Failures = append(result.Failures, "response "+strconv.Itoa(resp.StatusCode)+": "+problem)
+	}
+
+	return nil
+}
+
+// exampleRequest returns the request of a route, with the example path params, query and body.
+func exampleRequest(doc *Document, group *DocGroup, route *DocRoute) (*http.Request, error) {
+	var body io.Reader
+	if route.RequestBody != nil {
+		b, err := json.Marshal(requestExample(doc, route))
+		if err != nil {
+			return nil, errors.Wrap(err, "encoding request")
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(strings.ToUpper(route.Method), exampleURL(contractHost, group, route), body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req, nil
+}
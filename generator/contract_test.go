@@ -0,0 +1,152 @@
+package generator
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckContract(t *testing.T) {
+	doc := &Document{
+		Categories: []*DocCategory{
+			{Name: categoryHTTP, Groups: []*DocGroup{{
+				Name:   "Users",
+				Prefix: "/api",
+				Routes: []*DocRoute{{
+					Name:   "Update",
+					Method: http.MethodPut,
+					Path:   "/users/{id}{?tags,limit}",
+					Params: map[string]*DocValue{
+						"id":    {Value: "1", APIMDType: "string"},
+						"tags":  {Value: "a,b", APIMDType: "array"},
+						"limit": {Value: "10", APIMDType: "number", Opt: true},
+					},
+					RequestBody: map[string]interface{}{
+						"user":  &DocRef{Name: "User"},
+						"admin": &DocValue{Value: "true", APIMDType: "boolean"},
+					},
+					ResponseBodies: map[int]interface{}{
+						http.StatusOK:       &DocArray{Item: &DocRef{Name: "User"}},
+						http.StatusNotFound: nil,
+					},
+				}},
+			}}},
+			{Name: categoryFiredEvents, Groups: []*DocGroup{{
+				Name:   "Events",
+				Events: []*DocEvent{{Name: "Created", Direction: EventFired, EventName: "/user/created"}},
+			}}},
+		},
+		DataStructures: []*DocDataStructure{{Name: "User", Value: map[string]interface{}{
+			"age":        &DocValue{Value: "30", APIMDType: "number"},
+			"deleted_at": &DocValue{Null: true, Nullable: true, APIMDType: "string"},
+		}}},
+	}
+
+	var gotRequest string
+	response := ""
+	status := http.StatusOK
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		gotRequest = r.Method + " " + r.RequestURI + " " + string(body)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	})
+
+	response = `[{"age":31,"deleted_at":null,"undocumented":1}]`
+	results := CheckContract(doc, ContractHandler(handler))
+	if len(results) != 1 {
+		t.Fatalf("want a result for the http route only, got %v", len(results))
+	}
+	want := `PUT /api/users/1?limit=10&tags=a&tags=b {"admin":true,"user":{"age":30,"deleted_at":null}}`
+	if gotRequest != want {
+		t.Errorf("got request:\n%v\nwant:\n%v", gotRequest, want)
+	}
+	if result := results[0]; result.Method != http.MethodPut || result.Path != "/api/users/{id}{?tags,limit}" ||
+		result.StatusCode != http.StatusOK || len(result.Failures) != 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	response = `[{"age":"31"}]`
+	failures := CheckContract(doc, ContractHandler(handler))[0].Failures
+	wantFailures := []string{
+		"response 200: $[0].age: want number, got string",
+		"response 200: $[0].deleted_at: missing required field",
+	}
+	if strings.Join(failures, "\n") != strings.Join(wantFailures, "\n") {
+		t.Errorf("got failures:\n%v\nwant:\n%v", strings.Join(failures, "\n"), strings.Join(wantFailures, "\n"))
+	}
+
+	status = http.StatusNotFound
+	response = "not found"
+	if failures := CheckContract(doc, ContractHandler(handler))[0].Failures; len(failures) != 0 {
+		t.Errorf("documented status without body should pass, got %v", failures)
+	}
+
+	status = http.StatusInternalServerError
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	failures = CheckContract(doc, ContractServer(server))[0].Failures
+	if len(failures) != 1 || failures[0] != "undocumented status code: 500" {
+		t.Errorf("unexpected failures: %v", failures)
+	}
+}
+
+func TestValidateBody(t *testing.T) {
+	doc := &Document{DataStructures: []*DocDataStructure{{Name: "Node", Value: map[string]interface{}{
+		"children": &DocArray{Item: &DocRef{Name: "Node"}},
+	}}}}
+
+	for _, data := range []struct {
+		Tree interface{}
+		Body string
+		Want []string
+	}{
+		{
+			Tree: &DocValue{APIMDType: "string", Enum: []string{"a", "b"}},
+			Body: `"c"`,
+			Want: []string{`$: "c" is not one of the enum values`},
+		},
+		{
+			Tree: &DocValue{APIMDType: "number", Nullable: true},
+			Body: `null`,
+			Want: []string{},
+		},
+		{
+			Tree: map[string]interface{}{"id": &DocValue{APIMDType: "string", Opt: true}},
+			Body: `{}`,
+			Want: []string{},
+		},
+		{
+			Tree: &DocArray{Item: &DocValue{APIMDType: "number"}, MinItems: 3, UniqueItems: true},
+			Body: `[1, 1]`,
+			Want: []string{"$: want at least 3 items, got 2", "$: items are not unique"},
+		},
+		{
+			Tree: &DocOneOf{Variants: []interface{}{
+				map[string]interface{}{"card": &DocValue{APIMDType: "string"}},
+				map[string]interface{}{"iban": &DocValue{APIMDType: "string"}},
+			}},
+			Body: `{"card":1}`,
+			Want: []string{"$: does not match any of the One Of variants"},
+		},
+		{
+			Tree: &DocRef{Name: "Node"},
+			Body: `{"children":[{"children":[{"children":{}}]}]}`,
+			Want: []string{"$.children[0].children[0].children: want array, got object"},
+		},
+	} {
+		got, err := ValidateBody(doc, data.Tree, []byte(data.Body))
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if strings.Join(got, "\n") != strings.Join(data.Want, "\n") {
+			t.Errorf("%v: got %q want %q", data.Body, got, data.Want)
+		}
+	}
+
+	if _, err := ValidateBody(doc, &DocValue{}, []byte("{")); err == nil {
+		t.Errorf("want error for invalid JSON")
+	}
+}
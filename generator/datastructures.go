@@ -0,0 +1,239 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type hoister struct {
+	counts     map[string]int
+	hints      map[string]string
+	names      map[string]string
+	usedNames  map[string]bool
+	structures []*DocDataStructure
+}
+
+// hoistDataStructures replaces the objects which occur more than once in the request and response bodies with
+// references to named data structures. Objects are the same if they are identical, or if they are made from the same
+// go type and have the same shape, in which case the first occurrence is used as the example.
+func hoistDataStructures(doc *Document) {
+	h := &hoister{
+		counts:    make(map[string]int),
+		hints:     make(map[string]string),
+		names:     make(map[string]string),
+		usedNames: make(map[string]bool),
+	}
+
+	forEachBody(doc, func(body interface{}) interface{} {
+		h.count("", body)
+		return body
+	})
+	forEachBody(doc, h.hoist)
+
+	sort.Slice(h.structures, func(i, j int) bool {
+		return h.structures[i].Name < h.structures[j].Name
+	})
+	doc.DataStructures = h.structures
+}
+
+// forEachBody replaces every request, response and event body of doc with the result of fn.
+func forEachBody(doc *Document, fn func(body interface{}) interface{}) {
+	for _, category := range doc.Categories {
+		for _, group := range category.Groups {
+			for _, route := range group.Routes {
+				if route.RequestBody != nil {
+					route.RequestBody = fn(route.RequestBody)
+				}
+				for _, statusCode := range sortedStatusCodes(route.ResponseBodies) {
+					if body := route.ResponseBodies[statusCode]; body != nil {
+						route.ResponseBodies[statusCode] = fn(body)
+					}
+				}
+			}
+			for _, event := range group.Events {
+				if event.Payload != nil {
+					event.Payload = fn(event.Payload)
+				}
+			}
+		}
+	}
+}
+
+func (h *hoister) count(key string, v interface{}) {
+	switch vt := v.(type) {
+	case *typedDocTree:
+		h.countObject(key, vt.fields, vt.name)
+
+	case map[string]interface{}:
+		h.countObject(key, vt, "")
+
+	case *DocArray:
+		h.count(key, vt.Item)
+
+	case *DocOneOf:
+		for _, variant := range vt.Variants {
+			h.count(key, variant)
+		}
+	}
+}
+
+func (h *hoister) countObject(key string, fields map[string]interface{}, typeName string) {
+	groupKey := h.groupKey(fields, typeName)
+	h.counts[groupKey]++
+	if typeName != "" {
+		h.hints[groupKey] = typeName
+	} else if _, ok := h.hints[groupKey]; !ok {
+		h.hints[groupKey] = structureName(key)
+	}
+
+	for _, k := range sortedKeys(fields) {
+		h.count(k, fields[k])
+	}
+}
+
+func (h *hoister) hoist(v interface{}) interface{} {
+	switch vt := v.(type) {
+	case *typedDocTree:
+		return h.hoistObject(vt.fields, vt.name)
+
+	case map[string]interface{}:
+		return h.hoistObject(vt, "")
+
+	case *DocArray:
+		result := *vt
+		result.Item = h.hoist(vt.Item)
+
+		return &result
+
+	case *DocOneOf:
+		result := &DocOneOf{
+			Discriminator: vt.Discriminator,
+			Variants:      make([]interface{}, 0, len(vt.Variants)),
+		}
+		for _, variant := range vt.Variants {
+			result.Variants = append(result.Variants, h.hoist(variant))
+		}
+
+		return result
+
+	default:
+		return v
+	}
+}
+
+func (h *hoister) hoistObject(fields map[string]interface{}, typeName string) interface{} {
+	groupKey := h.groupKey(fields, typeName)
+	if name, ok := h.names[groupKey]; ok {
+		return &DocRef{Name: name}
+	}
+
+	result := make(map[string]interface{}, len(fields))
+	for _, k := range sortedKeys(fields) {
+		result[k] = h.hoist(fields[k])
+	}
+	if h.counts[groupKey] < 2 {
+		return result
+	}
+
+	name := h.uniqueName(h.hints[groupKey])
+	h.names[groupKey] = name
+	h.structures = append(h.structures, &DocDataStructure{Name: name, Value: result})
+
+	return &DocRef{Name: name}
+}
+
+func (h *hoister) groupKey(fields map[string]interface{}, typeName string) string {
+	if typeName != "" {
+		return "type " + typeName + " " + signature(fields, false)
+	}
+
+	return signature(fields, true)
+}
+
+func (h *hoister) uniqueName(name string) string {
+	result := name
+	for i := 2; h.usedNames[result]; i++ {
+		result = name + strconv.Itoa(i)
+	}
+	h.usedNames[result] = true
+
+	return result
+}
+
+// signature describes the tree v, example values and descriptions are only included if withValues is set.
+func signature(v interface{}, withValues bool) string {
+	switch vt := v.(type) {
+	case *typedDocTree:
+		return signature(vt.fields, withValues)
+
+	case map[string]interface{}:
+		parts := make([]string, 0, len(vt))
+		for _, k := range sortedKeys(vt) {
+			parts = append(parts, strconv.Quote(k)+":"+signature(vt[k], withValues))
+		}
+		return "{" + strings.Join(parts, ",") + "}"
+
+	case *DocArray:
+		return fmt.Sprintf("[%v,%v,%v]", vt.MinItems, vt.MaxItems, vt.UniqueItems) + "[" + signature(vt.Item, withValues) + "]"
+
+	case *DocOneOf:
+		parts := make([]string, 0, len(vt.Variants))
+		for _, variant := range vt.Variants {
+			parts = append(parts, signature(variant, withValues))
+		}
+		return "oneOf " + strconv.Quote(vt.Discriminator) + "(" + strings.Join(parts, ",") + ")"
+
+	case *DocValue:
+		dv := *vt
+		if !withValues {
+			dv.Value = ""
+			dv.Desc = ""
+		}
+		return fmt.Sprintf("%#v", dv)
+
+	default:
+		return fmt.Sprintf("%#v", v)
+	}
+}
+
+// structureName turns a property name like `user_id` into a data structure name like `UserId`.
+func structureName(key string) string {
+	parts := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(parts) == 0 {
+		return "Object"
+	}
+
+	result := ""
+	for _, part := range parts {
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		result += string(runes)
+	}
+
+	return result
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortedStatusCodes(m map[int]interface{}) []int {
+	statusCodes := make([]int, 0, len(m))
+	for statusCode := range m {
+		statusCodes = append(statusCodes, statusCode)
+	}
+	sort.Ints(statusCodes)
+
+	return statusCodes
+}
@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHoistDataStructures(t *testing.T) {
+	type user struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	type response struct {
+		Owner   user   `json:"owner"`
+		Members []user `json:"members"`
+	}
+
+	c := newCollector()
+	c.dataStructures = true
+	doc := mustCollect(t, c, &testDefinitions{groups: func(f *Factory) []Group {
+		return []Group{&HTTPGroup{
+			Name: "Users",
+			Routes: []*HTTPRoute{
+				{
+					Name:   "Get",
+					Method: http.MethodGet,
+					Path:   "/users/:id",
+					Request: struct {
+						ID string `param:"id"`
+					}{ID: f.Param("1").String()},
+					Responses: map[int]interface{}{http.StatusOK: user{ID: f.Body("1").String(), Name: f.Body("John").String()}},
+				},
+				{
+					Name:   "Team",
+					Method: http.MethodGet,
+					Path:   "/team",
+					Responses: map[int]interface{}{http.StatusOK: response{
+						Owner:   user{ID: f.Body("2").String(), Name: f.Body("Jane").String()},
+						Members: []user{{ID: f.Body("3").String(), Name: f.Body("Joe").String()}},
+					}},
+				},
+			},
+		}}
+	}})
+
+	if len(doc.DataStructures) != 1 || doc.DataStructures[0].Name != "user" {
+		t.Fatalf("want a single `user` data structure, got: %+v", doc.DataStructures)
+	}
+	if id := doc.DataStructures[0].Value.(map[string]interface{})["id"].(*DocValue).Value; id != "1" {
+		t.Errorf("want the first occurrence as example, got id: %s", id)
+	}
+
+	routes := doc.Categories[0].Groups[0].Routes
+	if ref, ok := routes[0].ResponseBodies[http.StatusOK].(*DocRef); !ok || ref.Name != "user" {
+		t.Errorf("want reference to `user`, got: %#v", routes[0].ResponseBodies[http.StatusOK])
+	}
+	team := routes[1].ResponseBodies[http.StatusOK].(map[string]interface{})
+	if _, ok := team["owner"].(*DocRef); !ok {
+		t.Errorf("want reference for owner, got: %#v", team["owner"])
+	}
+	if _, ok := team["members"].(*DocArray).Item.(*DocRef); !ok {
+		t.Errorf("want reference for members, got: %#v", team["members"])
+	}
+
+	// the data structures are the schema components of the OpenAPI document
+	b, err := MarshalOpenAPI(doc)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := validateOpenAPI(b); err != nil {
+		t.Fatalf("invalid OpenAPI document: %v\n%s", err, b)
+	}
+	var openAPI struct {
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(b, &openAPI); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if got, want := mustJSON(openAPI.Components.Schemas), `{"user":{"properties":{`+
+		`"id":{"examples":["1"],"type":"string"},"name":{"examples":["John"],"type":"string"}},`+
+		`"required":["id","name"],"type":"object"}}`; got != want {
+		t.Errorf("schema components:\ngot:  %v\nwant: %v", got, want)
+	}
+	if refs := strings.Count(string(b), `"$ref": "#/components/schemas/user"`); refs != 3 {
+		t.Errorf("want 3 references to user, got %v:\n%s", refs, b)
+	}
+}
+
+func TestCollectUntypedTrees(t *testing.T) {
+	type user struct {
+		ID string `json:"id"`
+	}
+	type page struct {
+		Users []user `json:"users"`
+	}
+
+	doc := mustCollect(t, newCollector(), &testDefinitions{groups: func(f *Factory) []Group {
+		return []Group{&HTTPGroup{
+			Name: "Users",
+			Routes: []*HTTPRoute{{
+				Name:   "List",
+				Method: http.MethodGet,
+				Path:   "/users",
+				Responses: map[int]interface{}{http.StatusOK: OneOf{Variants: []interface{}{
+					user{ID: f.Body("1").String()},
+					page{Users: []user{{ID: f.Body("2").String()}, {ID: f.Body("3").String()}}},
+				}}},
+			}},
+		}}
+	}})
+
+	oneOf := doc.Categories[0].Groups[0].Routes[0].ResponseBodies[http.StatusOK].(*DocOneOf)
+	if _, ok := oneOf.Variants[0].(map[string]interface{}); !ok {
+		t.Errorf("want untyped object, got: %#v", oneOf.Variants[0])
+	}
+	users := oneOf.Variants[1].(map[string]interface{})["users"].(*DocArray)
+	if _, ok := users.Item.(map[string]interface{}); !ok {
+		t.Errorf("want untyped array item, got: %#v", users.Item)
+	}
+}
+
+func TestStructureName(t *testing.T) {
+	for _, data := range []struct {
+		Key  string
+		Want string
+	}{
+		{Key: "pagination", Want: "Pagination"},
+		{Key: "user_id", Want: "UserId"},
+		{Key: "last-seen.at", Want: "LastSeenAt"},
+		{Key: "", Want: "Object"},
+	} {
+		got := structureName(data.Key)
+		if got != data.Want {
+			t.Errorf("key: `%s` want: `%s` got: `%s`", data.Key, data.Want, got)
+		}
+	}
+}
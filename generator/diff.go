@@ -0,0 +1,401 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ChangeRouteAdded      = "route-added"
+	ChangeRouteRemoved    = "route-removed"
+	ChangeStatusAdded     = "status-added"
+	ChangeStatusRemoved   = "status-removed"
+	ChangeParamAdded      = "param-added"
+	ChangeParamRemoved    = "param-removed"
+	ChangeFieldAdded      = "field-added"
+	ChangeFieldRemoved    = "field-removed"
+	ChangeTypeChanged     = "type-changed"
+	ChangeBecameRequired  = "became-required"
+	ChangeBecameOptional  = "became-optional"
+	ChangeBecameNullable  = "became-nullable"
+	ChangeNullableRemoved = "nullable-removed"
+	ChangeVariantAdded    = "variant-added"
+	ChangeVariantRemoved  = "variant-removed"
+	ChangeEventAdded      = "event-added"
+	ChangeEventRemoved    = "event-removed"
+	ChangeHeaderAdded     = "header-added"
+	ChangeHeaderRemoved   = "header-removed"
+)
+
+// Change is a single difference between two documents.
+// Route is the method and the full path of the route, or the direction and the name of an event, Location points to
+// the param or field within the route.
+type Change struct {
+	Kind     string `json:"kind"`
+	Breaking bool   `json:"breaking"`
+	Route    string `json:"route"`
+	Location string `json:"location,omitempty"`
+	Message  string `json:"message"`
+}
+
+type DiffReport struct {
+	Breaking bool      `json:"breaking"`
+	Changes  []*Change `json:"changes"`
+}
+
+type differ struct {
+	oldDoc  *Document
+	newDoc  *Document
+	changes []*Change
+}
+
+// Diff compares two versions of a document, and classifies the changes as breaking or non-breaking for the consumers.
+// Request bodies, params and consumed messages break consumers when they get stricter, responses and fired events
+// when they get looser.
+func Diff(oldDoc *Document, newDoc *Document) *DiffReport {
+	d := &differ{
+		oldDoc:  oldDoc,
+		newDoc:  newDoc,
+		changes: make([]*Change, 0),
+	}
+
+	oldRoutes := routesByKey(oldDoc)
+	newRoutes := routesByKey(newDoc)
+
+	for _, key := range sortedRouteKeys(oldRoutes) {
+		newRoute, ok := newRoutes[key]
+		if !ok {
+			d.add(ChangeRouteRemoved, true, key, "", "route removed")
+			continue
+		}
+		d.compareRoute(key, oldRoutes[key], newRoute)
+	}
+	for _, key := range sortedRouteKeys(newRoutes) {
+		if _, ok := oldRoutes[key]; !ok {
+			d.add(ChangeRouteAdded, false, key, "", "route added")
+		}
+	}
+
+	oldEvents := eventsByKey(oldDoc)
+	newEvents := eventsByKey(newDoc)
+
+	for _, key := range sortedEventKeys(oldEvents) {
+		newEvent, ok := newEvents[key]
+		if !ok {
+			d.add(ChangeEventRemoved, true, key, "", "event removed")
+			continue
+		}
+		d.compareEvent(key, oldEvents[key], newEvent)
+	}
+	for _, key := range sortedEventKeys(newEvents) {
+		if _, ok := oldEvents[key]; !ok {
+			d.add(ChangeEventAdded, false, key, "", "event added")
+		}
+	}
+
+	report := &DiffReport{Changes: d.changes}
+	for _, change := range d.changes {
+		report.Breaking = report.Breaking || change.Breaking
+	}
+
+	return report
+}
+
+// Summary returns the changes in a human readable form, breaking changes first.
+func (r *DiffReport) Summary() string {
+	breaking := 0
+	for _, change := range r.Changes {
+		if change.Breaking {
+			breaking++
+		}
+	}
+
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "%v breaking, %v non-breaking changes\n", breaking, len(r.Changes)-breaking)
+	for _, wantBreaking := range []bool{true, false} {
+		for _, change := range r.Changes {
+			if change.Breaking != wantBreaking {
+				continue
+			}
+
+			label := "non-breaking"
+			if change.Breaking {
+				label = "BREAKING"
+			}
+			location := ""
+			if change.Location != "" {
+				location = " " + change.Location
+			}
+			fmt.Fprintf(buf, "%-12s [%v]%v: %v\n", label, change.Route, location, change.Message)
+		}
+	}
+
+	return buf.String()
+}
+
+func routesByKey(doc *Document) map[string]*DocRoute {
+	result := make(map[string]*DocRoute)
+	for _, category := range doc.Categories {
+		for _, group := range category.Groups {
+			for _, route := range group.Routes {
+				result[routeKey(group, route)] = route
+			}
+		}
+	}
+
+	return result
+}
+
+// routeKey identifies a route by its method and full path, without the query parameters.
+func routeKey(group *DocGroup, route *DocRoute) string {
+	path := route.Path
+	if i := strings.Index(path, "{?"); i >= 0 {
+		path = path[:i]
+	}
+
+	return route.Method + " " + group.Prefix + path
+}
+
+func eventsByKey(doc *Document) map[string]*DocEvent {
+	result := make(map[string]*DocEvent)
+	forEachEvent(doc, func(category *DocCategory, group *DocGroup, event *DocEvent) {
+		result[eventKey(event)] = event
+	})
+
+	return result
+}
+
+// eventKey identifies an event by its direction and name, eg. FIRED /event/user/created/v1
+func eventKey(event *DocEvent) string {
+	return strings.ToUpper(event.Direction) + " " + event.EventName
+}
+
+func sortedEventKeys(events map[string]*DocEvent) []string {
+	keys := make([]string, 0, len(events))
+	for k := range events {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortedRouteKeys(routes map[string]*DocRoute) []string {
+	keys := make([]string, 0, len(routes))
+	for k := range routes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (d *differ) add(kind string, breaking bool, route string, location string, message string) {
+	d.changes = append(d.changes, &Change{
+		Kind:     kind,
+		Breaking: breaking,
+		Route:    route,
+		Location: location,
+		Message:  message,
+	})
+}
+
+func (d *differ) compareRoute(key string, oldRoute *DocRoute, newRoute *DocRoute) {
+	d.compareParams(key, oldRoute.Params, newRoute.Params)
+	d.compareParams(key, oldRoute.Query, newRoute.Query)
+
+	d.compareTree(key, "request", oldRoute.RequestBody, newRoute.RequestBody, true)
+
+	for _, statusCode := range sortedStatusCodes(oldRoute.ResponseBodies) {
+		location := "response " + strconv.Itoa(statusCode)
+		newBody, ok := newRoute.ResponseBodies[statusCode]
+		if !ok {
+			d.add(ChangeStatusRemoved, true, key, location, "status code removed")
+			continue
+		}
+		d.compareTree(key, location, oldRoute.ResponseBodies[statusCode], newBody, false)
+	}
+	for _, statusCode := range sortedStatusCodes(newRoute.ResponseBodies) {
+		if _, ok := oldRoute.ResponseBodies[statusCode]; !ok {
+			d.add(ChangeStatusAdded, false, key, "response "+strconv.Itoa(statusCode), "status code added")
+		}
+	}
+}
+
+// compareEvent compares two versions of an event, consumed events break like requests, fired events like responses.
+func (d *differ) compareEvent(key string, oldEvent *DocEvent, newEvent *DocEvent) {
+	request := newEvent.Direction == EventConsumed
+
+	for _, name := range sortedParamNames(oldEvent.Headers) {
+		location := "header `" + name + "`"
+		newHeader, ok := newEvent.Headers[name]
+		if !ok {
+			d.add(ChangeHeaderRemoved, !request, key, location, "header removed")
+			continue
+		}
+		d.compareValue(key, location, oldEvent.Headers[name], newHeader, request)
+	}
+	for _, name := range sortedParamNames(newEvent.Headers) {
+		if _, ok := oldEvent.Headers[name]; !ok {
+			required := !newEvent.Headers[name].Opt
+			message := "optional header added"
+			if required {
+				message = "required header added"
+			}
+			d.add(ChangeHeaderAdded, request && required, key, "header `"+name+"`", message)
+		}
+	}
+
+	d.compareTree(key, "payload", oldEvent.Payload, newEvent.Payload, request)
+}
+
+func (d *differ) compareParams(key string, oldParams map[string]*DocValue, newParams map[string]*DocValue) {
+	for _, name := range sortedParamNames(oldParams) {
+		location := "param `" + name + "`"
+		newParam, ok := newParams[name]
+		if !ok {
+			d.add(ChangeParamRemoved, false, key, location, "param removed")
+			continue
+		}
+		d.compareValue(key, location, oldParams[name], newParam, true)
+	}
+	for _, name := range sortedParamNames(newParams) {
+		if _, ok := oldParams[name]; !ok {
+			required := !newParams[name].Opt
+			message := "optional param added"
+			if required {
+				message = "required param added"
+			}
+			d.add(ChangeParamAdded, required, key, "param `"+name+"`", message)
+		}
+	}
+}
+
+func sortedParamNames(params map[string]*DocValue) []string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// compareTree compares two body trees, request is set for the trees which are sent by the consumers.
+func (d *differ) compareTree(key string, location string, oldTree interface{}, newTree interface{}, request bool) {
+	oldTree = d.resolve(d.oldDoc, oldTree)
+	newTree = d.resolve(d.newDoc, newTree)
+
+	if oldTree == nil || newTree == nil {
+		switch {
+		case oldTree != nil:
+			d.add(ChangeFieldRemoved, !request, key, location, "body removed")
+		case newTree != nil:
+			d.add(ChangeFieldAdded, request, key, location, "body added")
+		}
+		return
+	}
+
+	if oldKind, newKind := treeKind(oldTree), treeKind(newTree); oldKind != newKind {
+		d.add(ChangeTypeChanged, true, key, location, fmt.Sprintf("type changed from %v to %v", oldKind, newKind))
+		return
+	}
+
+	switch ot := oldTree.(type) {
+	case *DocValue:
+		d.compareValue(key, location, ot, newTree.(*DocValue), request)
+
+	case *DocArray:
+		nt := newTree.(*DocArray)
+		d.compareTree(key, location+"[]", ot.Item, nt.Item, request)
+
+	case *DocOneOf:
+		nt := newTree.(*DocOneOf)
+		for i := 0; i < len(ot.Variants) && i < len(nt.Variants); i++ {
+			d.compareTree(key, location+" variant "+strconv.Itoa(i), ot.Variants[i], nt.Variants[i], request)
+		}
+		for i := len(nt.Variants); i < len(ot.Variants); i++ {
+			d.add(ChangeVariantRemoved, request, key, location+" variant "+strconv.Itoa(i), "variant removed")
+		}
+		for i := len(ot.Variants); i < len(nt.Variants); i++ {
+			d.add(ChangeVariantAdded, !request, key, location+" variant "+strconv.Itoa(i), "variant added")
+		}
+
+	case map[string]interface{}:
+		nt := newTree.(map[string]interface{})
+		for _, k := range sortedKeys(ot) {
+			fieldLocation := location + " `" + k + "`"
+			newField, ok := nt[k]
+			if !ok {
+				d.add(ChangeFieldRemoved, !request, key, fieldLocation, "field removed")
+				continue
+			}
+			d.compareTree(key, fieldLocation, ot[k], newField, request)
+		}
+		for _, k := range sortedKeys(nt) {
+			if _, ok := ot[k]; ok {
+				continue
+			}
+
+			// only required fields of requests break the consumers
+			newField, _ := d.resolve(d.newDoc, nt[k]).(*DocValue)
+			required := newField == nil || !newField.Opt
+			message := "optional field added"
+			if required {
+				message = "required field added"
+			}
+			d.add(ChangeFieldAdded, request && required, key, location+" `"+k+"`", message)
+		}
+	}
+}
+
+func (d *differ) compareValue(key string, location string, oldValue *DocValue, newValue *DocValue, request bool) {
+	if oldValue.APIMDType != newValue.APIMDType {
+		d.add(ChangeTypeChanged, true, key, location, fmt.Sprintf("type changed from %v to %v", oldValue.APIMDType, newValue.APIMDType))
+	}
+
+	switch {
+	case oldValue.Opt && !newValue.Opt:
+		d.add(ChangeBecameRequired, request, key, location, "optional value became required")
+	case !oldValue.Opt && newValue.Opt:
+		d.add(ChangeBecameOptional, !request, key, location, "required value became optional")
+	}
+
+	switch {
+	case !oldValue.Nullable && newValue.Nullable:
+		d.add(ChangeBecameNullable, !request, key, location, "value became nullable")
+	case oldValue.Nullable && !newValue.Nullable:
+		d.add(ChangeNullableRemoved, request, key, location, "value is not nullable anymore")
+	}
+}
+
+// resolve replaces data structure references with the referenced trees.
+func (d *differ) resolve(doc *Document, tree interface{}) interface{} {
+	ref, ok := tree.(*DocRef)
+	if !ok {
+		return tree
+	}
+
+	for _, ds := range doc.DataStructures {
+		if ds.Name == ref.Name {
+			return ds.Value
+		}
+	}
+
+	return nil
+}
+
+func treeKind(tree interface{}) string {
+	switch t := tree.(type) {
+	case *DocValue:
+		return t.APIMDType
+	case *DocArray:
+		return "array"
+	case *DocOneOf:
+		return "one of"
+	default:
+		return "object"
+	}
+}
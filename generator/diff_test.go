@@ -0,0 +1,132 @@
+package generator
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	oldDoc := &Document{Categories: []*DocCategory{{Name: "Http", Groups: []*DocGroup{{
+		Name: "Users",
+		Routes: []*DocRoute{
+			{
+				Method: "GET",
+				Path:   "/users/{id}{?fields}",
+				Params: map[string]*DocValue{
+					"id":     {Value: "1", APIMDType: "string"},
+					"fields": {Value: "name", APIMDType: "string", Opt: true},
+				},
+				ResponseBodies: map[int]interface{}{
+					200: map[string]interface{}{
+						"id":    &DocValue{Value: "1", APIMDType: "string"},
+						"email": &DocValue{Value: "a@b.c", APIMDType: "string"},
+						"age":   &DocValue{Value: "30", APIMDType: "number"},
+					},
+					404: nil,
+				},
+			},
+			{
+				Method: "DELETE",
+				Path:   "/users/{id}",
+			},
+		},
+	}}}}}
+
+	newDoc := &Document{Categories: []*DocCategory{{Name: "Http", Groups: []*DocGroup{{
+		Name: "Users",
+		Routes: []*DocRoute{
+			{
+				Method: "GET",
+				Path:   "/users/{id}{?fields,lang}",
+				Params: map[string]*DocValue{
+					"id":     {Value: "1", APIMDType: "string"},
+					"fields": {Value: "name", APIMDType: "string"},
+					"lang":   {Value: "en", APIMDType: "string", Opt: true},
+				},
+				ResponseBodies: map[int]interface{}{
+					200: map[string]interface{}{
+						"id":   &DocValue{Value: "1", APIMDType: "string"},
+						"age":  &DocValue{Value: "30", APIMDType: "string"},
+						"name": &DocValue{Value: "John", APIMDType: "string"},
+					},
+				},
+			},
+			{
+				Method: "POST",
+				Path:   "/users",
+			},
+		},
+	}}}}}
+
+	report := Diff(oldDoc, newDoc)
+	if !report.Breaking {
+		t.Errorf("want breaking report")
+	}
+
+	got := make([]string, 0, len(report.Changes))
+	for _, change := range report.Changes {
+		got = append(got, change.Kind+" "+strings.TrimSpace(change.Route+" "+change.Location)+" "+strconv.FormatBool(change.Breaking))
+	}
+	want := []string{
+		"route-removed DELETE /users/{id} true",
+		"became-required GET /users/{id} param `fields` true",
+		"param-added GET /users/{id} param `lang` false",
+		"type-changed GET /users/{id} response 200 `age` true",
+		"field-removed GET /users/{id} response 200 `email` true",
+		"field-added GET /users/{id} response 200 `name` false",
+		"status-removed GET /users/{id} response 404 true",
+		"route-added POST /users false",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("want:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	if summary := report.Summary(); !strings.HasPrefix(summary, "5 breaking, 3 non-breaking changes\n") {
+		t.Errorf("unexpected summary:\n%s", summary)
+	}
+}
+
+func TestDiffEvents(t *testing.T) {
+	payload := func(fields ...string) interface{} {
+		result := make(map[string]interface{})
+		for _, field := range fields {
+			result[field] = &DocValue{Value: "1", APIMDType: "string"}
+		}
+		return result
+	}
+	trace := map[string]*DocValue{"trace_id": {Value: "t1", APIMDType: "string"}}
+
+	oldDoc := &Document{Categories: []*DocCategory{{Name: categoryConsumedMessages, Groups: []*DocGroup{{
+		Name: "Events",
+		Events: []*DocEvent{
+			{Direction: EventConsumed, EventName: "/user/deleted/v1", Payload: payload("id")},
+			{Direction: EventFired, EventName: "/user/created/v1", Headers: trace, Payload: payload("id", "name")},
+			{Direction: EventFired, EventName: "/user/updated/v1"},
+		},
+	}}}}}
+	newDoc := &Document{Categories: []*DocCategory{{Name: categoryConsumedMessages, Groups: []*DocGroup{{
+		Name: "Events",
+		Events: []*DocEvent{
+			{Direction: EventConsumed, EventName: "/user/deleted/v1", Headers: trace, Payload: payload("id", "reason")},
+			{Direction: EventFired, EventName: "/user/created/v1", Payload: payload("id")},
+			{Direction: EventConsumed, EventName: "/user/updated/v1"},
+		},
+	}}}}}
+
+	got := make([]string, 0)
+	for _, change := range Diff(oldDoc, newDoc).Changes {
+		got = append(got, change.Kind+" "+strings.TrimSpace(change.Route+" "+change.Location)+" "+strconv.FormatBool(change.Breaking))
+	}
+	want := []string{
+		"header-added CONSUMED /user/deleted/v1 header `trace_id` true",
+		"field-added CONSUMED /user/deleted/v1 payload `reason` true",
+		"header-removed FIRED /user/created/v1 header `trace_id` true",
+		"field-removed FIRED /user/created/v1 payload `name` true",
+		"event-removed FIRED /user/updated/v1 true",
+		"event-added CONSUMED /user/updated/v1 false",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("want:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
@@ -1,9 +1,15 @@
 package generator
 
+import (
+	"strconv"
+	"strings"
+)
+
 type Document struct {
-	Name       string
-	Usage      []string
-	Categories []*DocCategory
+	Name           string
+	Usage          []string
+	Categories     []*DocCategory
+	DataStructures []*DocDataStructure
 }
 
 type DocCategory struct {
@@ -11,10 +17,12 @@ type DocCategory struct {
 	Groups []*DocGroup
 }
 
+// DocGroup is a group of http routes, or a group of events.
 type DocGroup struct {
 	Name   string
 	Prefix string
 	Routes []*DocRoute
+	Events []*DocEvent
 }
 
 type DocRoute struct {
@@ -26,11 +34,114 @@ type DocRoute struct {
 	Query          map[string]*DocValue
 	RequestBody    interface{}
 	ResponseBodies map[int]interface{}
+	Snippets       []*DocSnippet
+
+	// RequestExample and ResponseExamples are the bodies as JSON values, eg. map[string]interface{} and
+	// json.Number, built from the example values of the trees.
+	RequestExample   interface{}
+	ResponseExamples map[int]interface{}
+}
+
+// DocEvent is a GEB event, or a Centrifuge channel publication, consumed or fired by the service.
+type DocEvent struct {
+	Name string
+	// Direction is EventConsumed or EventFired
+	Direction string
+	// EventName is the full name of a GEB event, or the namespace:channel of a Centrifuge channel
+	EventName   string
+	Channel     *DocChannel
+	Description []string
+	Headers     map[string]*DocValue
+	Payload     interface{}
+
+	// PayloadExample is the payload as a JSON value, see: DocRoute.RequestExample
+	PayloadExample interface{}
+}
+
+// DocChannel is the Centrifuge channel of an event, it is nil for GEB events.
+type DocChannel struct {
+	Namespace string
+	Name      string
 }
 
 type DocValue struct {
 	Value     string
 	Desc      string
 	Opt       bool
+	Nullable  bool
+	Null      bool
 	APIMDType string
+	// Enum is the list of the possible values, if they are restricted
+	Enum []string
+}
+
+// DocArray is an array body, summarized with its first element.
+type DocArray struct {
+	Item        interface{}
+	MinItems    int
+	MaxItems    int
+	UniqueItems bool
+}
+
+type DocOneOf struct {
+	Discriminator string
+	Variants      []interface{}
+}
+
+// DocSnippet is a code example of a route, rendered as a fenced code block with Lang as its info string.
+type DocSnippet struct {
+	Lang string
+	Code string
+}
+
+type DocDataStructure struct {
+	Name  string
+	Value interface{}
+}
+
+type DocRef struct {
+	Name string
+}
+
+// eventLabel describes the direction and the transport of an event, eg. Fired GEB event
+func eventLabel(event *DocEvent) string {
+	transport := "GEB"
+	if event.Channel != nil {
+		transport = "Centrifuge"
+	}
+	direction := event.Direction
+	if direction != "" {
+		direction = strings.ToUpper(direction[:1]) + direction[1:]
+	}
+
+	return direction + " " + transport + " event"
+}
+
+// arrayType returns the type of a, eg. array[string], array[object] or array[User].
+func arrayType(a *DocArray) string {
+	switch item := a.Item.(type) {
+	case *DocValue:
+		return "array[" + item.APIMDType + "]"
+	case *DocRef:
+		return "array[" + item.Name + "]"
+	case *DocArray:
+		return "array[array]"
+	default:
+		return "array[object]"
+	}
+}
+
+func arrayConstraints(a *DocArray) string {
+	constraints := make([]string, 0, 3)
+	if a.MinItems > 0 {
+		constraints = append(constraints, "min items: "+strconv.Itoa(a.MinItems))
+	}
+	if a.MaxItems > 0 {
+		constraints = append(constraints, "max items: "+strconv.Itoa(a.MaxItems))
+	}
+	if a.UniqueItems {
+		constraints = append(constraints, "unique items")
+	}
+
+	return strings.Join(constraints, ", ")
 }
@@ -5,7 +5,10 @@ import (
 	"github.com/pkg/errors"
 )
 
-var jsons = []jsoniter.API{newJSON("param"), newJSON("query"), newJSON("json"), newJSON("geb"), newJSON("centrifuge")}
+var (
+	tagKeys = []string{"param", "query", "json", "geb", "centrifuge"}
+	jsons   = newJSONs(tagKeys)
+)
 
 func encDec(v interface{}) (interface{}, error) {
 	outM := make(map[string]interface{})
@@ -32,6 +35,15 @@ func encDec(v interface{}) (interface{}, error) {
 	return outM, nil
 }
 
+func newJSONs(tags []string) []jsoniter.API {
+	result := make([]jsoniter.API, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, newJSON(tag))
+	}
+
+	return result
+}
+
 func newJSON(tag string) jsoniter.API {
 	return jsoniter.Config{
 		EscapeHTML:             true,
@@ -0,0 +1,221 @@
+package generator
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var docPathParamRegex = regexp.MustCompile(`{(\w+)}`)
+
+// exampleParam is a query param of a route, with one of its example values.
+type exampleParam struct {
+	Name  string
+	Value string
+	Desc  string
+	Opt   bool
+}
+
+// exampleValue returns the example of a document tree as a JSON value: the values are converted to their
+// APIMDType, arrays contain their single documented element, One Ofs are replaced with their first variant and the
+// data structures are resolved.
+func exampleValue(doc *Document, tree interface{}) interface{} {
+	switch t := tree.(type) {
+	case *DocValue:
+		if t.Null {
+			return nil
+		}
+		switch t.APIMDType {
+		case "number":
+			if n := json.Number(t.Value); isJSONNumber(n) {
+				return n
+			}
+		case "boolean":
+			if t.Value == "true" || t.Value == "false" {
+				return t.Value == "true"
+			}
+		}
+		return t.Value
+
+	case *DocArray:
+		if t.Item == nil {
+			return []interface{}{}
+		}
+		return []interface{}{exampleValue(doc, t.Item)}
+
+	case *DocOneOf:
+		if len(t.Variants) == 0 {
+			return nil
+		}
+		return exampleValue(doc, t.Variants[0])
+
+	case *DocRef:
+		for _, ds := range doc.DataStructures {
+			if ds.Name == t.Name {
+				return exampleValue(doc, ds.Value)
+			}
+		}
+		return map[string]interface{}{}
+
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			result[k] = exampleValue(doc, v)
+		}
+		return result
+
+	default:
+		return nil
+	}
+}
+
+// addExamples sets the examples of the bodies of the routes and events which don't have one yet, see: exampleValue
+func addExamples(doc *Document) {
+	forEachEvent(doc, func(category *DocCategory, group *DocGroup, event *DocEvent) {
+		if event.PayloadExample == nil && event.Payload != nil {
+			event.PayloadExample = exampleValue(doc, event.Payload)
+		}
+	})
+	forEachRoute(doc, func(category *DocCategory, group *DocGroup, route *DocRoute) {
+		if route.RequestExample == nil && route.RequestBody != nil {
+			route.RequestExample = exampleValue(doc, route.RequestBody)
+		}
+		for statusCode, body := range route.ResponseBodies {
+			if body == nil {
+				continue
+			}
+			if route.ResponseExamples == nil {
+				route.ResponseExamples = make(map[int]interface{})
+			}
+			if _, ok := route.ResponseExamples[statusCode]; !ok {
+				route.ResponseExamples[statusCode] = exampleValue(doc, body)
+			}
+		}
+	})
+}
+
+// requestExample returns the example of the request body of route, also for documents without examples.
+func requestExample(doc *Document, route *DocRoute) interface{} {
+	if route.RequestExample != nil {
+		return route.RequestExample
+	}
+
+	return exampleValue(doc, route.RequestBody)
+}
+
+// payloadExample returns the example of the payload of event, also for documents without examples.
+func payloadExample(doc *Document, event *DocEvent) interface{} {
+	if event.PayloadExample != nil {
+		return event.PayloadExample
+	}
+
+	return exampleValue(doc, event.Payload)
+}
+
+// responseExample returns the example of a response body of route, also for documents without examples.
+func responseExample(doc *Document, route *DocRoute, statusCode int) interface{} {
+	if example, ok := route.ResponseExamples[statusCode]; ok {
+		return example
+	}
+
+	return exampleValue(doc, route.ResponseBodies[statusCode])
+}
+
+func indentJSON(v interface{}) (string, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return string(b), nil
+}
+
+func isJSONNumber(n json.Number) bool {
+	var v float64
+	return json.Unmarshal([]byte(n), &v) == nil && strings.TrimSpace(string(n)) == string(n)
+}
+
+// routePath returns the full path of a route without the query params, with the placeholders replaced by
+// replace, eg. routePath(group, route, func(name string) string { return ":" + name }) for /users/:id.
+func routePath(group *DocGroup, route *DocRoute, replace func(name string) string) string {
+	path := group.Prefix + route.Path
+	if i := strings.Index(path, "{?"); i >= 0 {
+		path = path[:i]
+	}
+	// the part after # only tells routes apart in the document
+	if i := strings.Index(path, "#"); i >= 0 {
+		path = path[:i]
+	}
+
+	return docPathParamRegex.ReplaceAllStringFunc(path, func(placeholder string) string {
+		return replace(placeholder[1 : len(placeholder)-1])
+	})
+}
+
+// routeQueryNames returns the names of the query params of a route, the collector lists them in the path, and
+// stores them with the path params.
+func routeQueryNames(route *DocRoute) []string {
+	i := strings.Index(route.Path, "{?")
+	if i < 0 || !strings.HasSuffix(route.Path, "}") {
+		return []string{}
+	}
+
+	return strings.Split(route.Path[i+2:len(route.Path)-1], ",")
+}
+
+// exampleQuery returns the query params of a route with their example values, array params once for every value.
+func exampleQuery(route *DocRoute) []*exampleParam {
+	names := routeQueryNames(route)
+	sort.Strings(names)
+
+	result := make([]*exampleParam, 0, len(names))
+	for _, name := range names {
+		value, ok := route.Params[name]
+		if !ok {
+			value, ok = route.Query[name]
+		}
+		if !ok {
+			result = append(result, &exampleParam{Name: name})
+			continue
+		}
+
+		values := []string{value.Value}
+		if value.APIMDType == "array" {
+			values = strings.Split(value.Value, ",")
+		}
+		for _, v := range values {
+			result = append(result, &exampleParam{Name: name, Value: v, Desc: value.Desc, Opt: value.Opt})
+		}
+	}
+
+	return result
+}
+
+// exampleURL returns the URL of a route with the example path params and query.
+func exampleURL(baseURL string, group *DocGroup, route *DocRoute) string {
+	result := strings.TrimSuffix(baseURL, "/") + routePath(group, route, func(name string) string {
+		return url.PathEscape(examplePathParam(route, name).Value)
+	})
+	query := url.Values{}
+	for _, param := range exampleQuery(route) {
+		query.Add(param.Name, param.Value)
+	}
+	if len(query) > 0 {
+		result += "?" + query.Encode()
+	}
+
+	return result
+}
+
+// examplePathParam returns the example value of a path param.
+func examplePathParam(route *DocRoute, name string) *DocValue {
+	if value, ok := route.Params[name]; ok {
+		return value
+	}
+
+	return &DocValue{}
+}
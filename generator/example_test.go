@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testExamplesDocument returns a document with a data structure, a null value and a response without body.
+func testExamplesDocument() *Document {
+	return &Document{
+		Name: "Users",
+		Categories: []*DocCategory{{Name: categoryHTTP, Groups: []*DocGroup{{
+			Name:   "Users",
+			Prefix: "/api",
+			Routes: []*DocRoute{{
+				Name:   "Update",
+				Method: http.MethodPut,
+				Path:   "/users/{id}",
+				Params: map[string]*DocValue{"id": {Value: "1", APIMDType: "string"}},
+				RequestBody: map[string]interface{}{
+					"user":  &DocRef{Name: "User"},
+					"admin": &DocValue{Value: "true", APIMDType: "boolean"},
+				},
+				ResponseBodies: map[int]interface{}{
+					http.StatusOK:       &DocArray{Item: &DocRef{Name: "User"}},
+					http.StatusNotFound: nil,
+				},
+			}},
+		}}}},
+		DataStructures: []*DocDataStructure{{Name: "User", Value: map[string]interface{}{
+			"age":        &DocValue{Value: "30", APIMDType: "number"},
+			"deleted_at": &DocValue{Null: true, Nullable: true, APIMDType: "string"},
+		}}},
+	}
+}
+
+func TestExampleValue(t *testing.T) {
+	doc := testExamplesDocument()
+	route := doc.Categories[0].Groups[0].Routes[0]
+
+	if got, want := mustJSON(exampleValue(doc, route.RequestBody)), `{"admin":true,"user":{"age":30,"deleted_at":null}}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	oneOf := &DocOneOf{Variants: []interface{}{
+		map[string]interface{}{"card": &DocValue{Value: "4111", APIMDType: "string"}},
+		map[string]interface{}{"iban": &DocValue{Value: "DE12", APIMDType: "string"}},
+	}}
+	if got := mustJSON(exampleValue(doc, oneOf)); got != `{"card":"4111"}` {
+		t.Errorf("One Of example should be the first variant, got %s", got)
+	}
+
+	for _, v := range []*DocValue{
+		{Value: "ten", APIMDType: "number"},
+		{Value: "yes", APIMDType: "boolean"},
+	} {
+		if got := exampleValue(doc, v); got != v.Value {
+			t.Errorf("invalid %v example %q should be kept as string, got %#v", v.APIMDType, v.Value, got)
+		}
+	}
+}
+
+func TestAddExamples(t *testing.T) {
+	doc := testExamplesDocument()
+	route := doc.Categories[0].Groups[0].Routes[0]
+	route.ResponseExamples = map[int]interface{}{http.StatusOK: "kept"}
+
+	addExamples(doc)
+
+	if got, want := mustJSON(route.RequestExample), `{"admin":true,"user":{"age":30,"deleted_at":null}}`; got != want {
+		t.Errorf("request example: got %s, want %s", got, want)
+	}
+	if got := route.ResponseExamples[http.StatusOK]; got != "kept" {
+		t.Errorf("existing response example should be kept, got %#v", got)
+	}
+	if _, ok := route.ResponseExamples[http.StatusNotFound]; ok {
+		t.Errorf("response without body should have no example")
+	}
+
+	route.RequestExample = nil
+	addExamples(doc)
+
+	b, err := RenderAPIMD(doc)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	body := "    + Body\n\n" +
+		"            {\n" +
+		"              \"admin\": true,\n"
+	if !strings.Contains(string(b), body) {
+		t.Errorf("rendered document should contain the request body example:\n%s", b)
+	}
+
+	parsed, err := ParseAPIMD(b)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	parsedRoute := parsed.Categories[0].Groups[0].Routes[0]
+	var want interface{}
+	if err := json.Unmarshal([]byte(mustJSON(route.RequestExample)), &want); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	var got interface{}
+	if err := json.Unmarshal([]byte(mustJSON(parsedRoute.RequestExample)), &got); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsed request example: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,591 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DocumentJSONVersion is the version of the JSON format of MarshalDocument.
+// It is increased on every change which older loaders could not read.
+// Version 2 documents the events as events, instead of routes.
+const DocumentJSONVersion = 2
+
+type exportEnvelope struct {
+	Version  int             `json:"version"`
+	Document *exportDocument `json:"document"`
+}
+
+type exportDocument struct {
+	Name           string                 `json:"name"`
+	Usage          []string               `json:"usage"`
+	Categories     []*exportCategory      `json:"categories"`
+	DataStructures []*exportDataStructure `json:"dataStructures,omitempty"`
+}
+
+type exportCategory struct {
+	Name   string         `json:"name"`
+	Groups []*exportGroup `json:"groups"`
+}
+
+type exportGroup struct {
+	Name   string         `json:"name"`
+	Prefix string         `json:"prefix"`
+	Routes []*exportRoute `json:"routes"`
+	Events []*exportEvent `json:"events,omitempty"`
+}
+
+type exportEvent struct {
+	Name        string                  `json:"name"`
+	Direction   string                  `json:"direction"`
+	EventName   string                  `json:"eventName"`
+	Channel     *exportChannel          `json:"channel,omitempty"`
+	Description []string                `json:"description,omitempty"`
+	Headers     map[string]*exportValue `json:"headers,omitempty"`
+	Payload     *exportNode             `json:"payload,omitempty"`
+
+	PayloadExample json.RawMessage `json:"payloadExample,omitempty"`
+}
+
+type exportChannel struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type exportRoute struct {
+	Name        string                  `json:"name"`
+	Method      string                  `json:"method"`
+	Path        string                  `json:"path"`
+	Description []string                `json:"description,omitempty"`
+	Params      map[string]*exportValue `json:"params,omitempty"`
+	Query       map[string]*exportValue `json:"query,omitempty"`
+	Request     *exportNode             `json:"request,omitempty"`
+	Responses   map[int]*exportNode     `json:"responses,omitempty"`
+	Snippets    []*exportSnippet        `json:"snippets,omitempty"`
+
+	RequestExample   json.RawMessage         `json:"requestExample,omitempty"`
+	ResponseExamples map[int]json.RawMessage `json:"responseExamples,omitempty"`
+}
+
+type exportSnippet struct {
+	Lang string `json:"lang"`
+	Code string `json:"code"`
+}
+
+type exportDataStructure struct {
+	Name  string      `json:"name"`
+	Value *exportNode `json:"value"`
+}
+
+// exportNode is a tree node, exactly one of its fields is set.
+type exportNode struct {
+	Object map[string]*exportNode `json:"object,omitempty"`
+	Array  *exportArray           `json:"array,omitempty"`
+	Value  *exportValue           `json:"value,omitempty"`
+	OneOf  *exportOneOf           `json:"oneOf,omitempty"`
+	Ref    string                 `json:"ref,omitempty"`
+}
+
+type exportArray struct {
+	Item        *exportNode `json:"item"`
+	MinItems    int         `json:"minItems,omitempty"`
+	MaxItems    int         `json:"maxItems,omitempty"`
+	UniqueItems bool        `json:"uniqueItems,omitempty"`
+}
+
+type exportOneOf struct {
+	Discriminator string        `json:"discriminator,omitempty"`
+	Variants      []*exportNode `json:"variants"`
+}
+
+type exportValue struct {
+	Value    string   `json:"value"`
+	Desc     string   `json:"desc,omitempty"`
+	Opt      bool     `json:"opt,omitempty"`
+	Nullable bool     `json:"nullable,omitempty"`
+	Null     bool     `json:"null,omitempty"`
+	Type     string   `json:"type"`
+	Enum     []string `json:"enum,omitempty"`
+}
+
+// MarshalDocument serializes doc to versioned JSON, which can be read back with UnmarshalDocument.
+func MarshalDocument(doc *Document) ([]byte, error) {
+	d := &exportDocument{
+		Name:           doc.Name,
+		Usage:          doc.Usage,
+		Categories:     make([]*exportCategory, 0, len(doc.Categories)),
+		DataStructures: make([]*exportDataStructure, 0, len(doc.DataStructures)),
+	}
+
+	for _, category := range doc.Categories {
+		c := &exportCategory{
+			Name:   category.Name,
+			Groups: make([]*exportGroup, 0, len(category.Groups)),
+		}
+		for _, group := range category.Groups {
+			g := &exportGroup{
+				Name:   group.Name,
+				Prefix: group.Prefix,
+				Routes: make([]*exportRoute, 0, len(group.Routes)),
+			}
+			for _, route := range group.Routes {
+				r, err := exportDocRoute(route)
+				if err != nil {
+					return nil, errors.Wrapf(err, "exporting route: [%v] %v", route.Method, route.Path)
+				}
+				g.Routes = append(g.Routes, r)
+			}
+			for _, event := range group.Events {
+				e, err := exportDocEvent(event)
+				if err != nil {
+					return nil, errors.Wrapf(err, "exporting event: %v", event.EventName)
+				}
+				g.Events = append(g.Events, e)
+			}
+			c.Groups = append(c.Groups, g)
+		}
+		d.Categories = append(d.Categories, c)
+	}
+
+	for _, ds := range doc.DataStructures {
+		value, err := exportTree(ds.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "exporting data structure: %v", ds.Name)
+		}
+		d.DataStructures = append(d.DataStructures, &exportDataStructure{Name: ds.Name, Value: value})
+	}
+
+	b, err := json.MarshalIndent(&exportEnvelope{Version: DocumentJSONVersion, Document: d}, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return b, nil
+}
+
+// UnmarshalDocument reads a document serialized by MarshalDocument.
+func UnmarshalDocument(data []byte) (*Document, error) {
+	envelope := &exportEnvelope{}
+	err := json.Unmarshal(data, envelope)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if envelope.Version < 1 || envelope.Version > DocumentJSONVersion {
+		return nil, errors.Errorf("unsupported document version: %v", envelope.Version)
+	}
+	if envelope.Document == nil {
+		return nil, errors.New("missing document")
+	}
+
+	d := envelope.Document
+	doc := &Document{
+		Name:       d.Name,
+		Usage:      d.Usage,
+		Categories: make([]*DocCategory, 0, len(d.Categories)),
+	}
+
+	for _, c := range d.Categories {
+		category := &DocCategory{
+			Name:   c.Name,
+			Groups: make([]*DocGroup, 0, len(c.Groups)),
+		}
+		for _, g := range c.Groups {
+			group := &DocGroup{
+				Name:   g.Name,
+				Prefix: g.Prefix,
+				Routes: make([]*DocRoute, 0, len(g.Routes)),
+			}
+			for _, r := range g.Routes {
+				route, err := importDocRoute(r)
+				if err != nil {
+					return nil, errors.Wrapf(err, "importing route: [%v] %v", r.Method, r.Path)
+				}
+				group.Routes = append(group.Routes, route)
+			}
+			if len(g.Events) > 0 {
+				group.Events = make([]*DocEvent, 0, len(g.Events))
+			}
+			for _, e := range g.Events {
+				event, err := importDocEvent(e)
+				if err != nil {
+					return nil, errors.Wrapf(err, "importing event: %v", e.EventName)
+				}
+				group.Events = append(group.Events, event)
+			}
+			category.Groups = append(category.Groups, group)
+		}
+		doc.Categories = append(doc.Categories, category)
+	}
+
+	for _, ds := range d.DataStructures {
+		value, err := importTree(ds.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "importing data structure: %v", ds.Name)
+		}
+		doc.DataStructures = append(doc.DataStructures, &DocDataStructure{Name: ds.Name, Value: value})
+	}
+	if envelope.Version < 2 {
+		upgradeEventRoutes(doc)
+	}
+	// exports written before the examples were added have none
+	addExamples(doc)
+
+	return doc, nil
+}
+
+func exportDocRoute(route *DocRoute) (*exportRoute, error) {
+	r := &exportRoute{
+		Name:        route.Name,
+		Method:      route.Method,
+		Path:        route.Path,
+		Description: route.Description,
+		Params:      exportValues(route.Params),
+		Query:       exportValues(route.Query),
+		Responses:   make(map[int]*exportNode, len(route.ResponseBodies)),
+	}
+	for _, snippet := range route.Snippets {
+		r.Snippets = append(r.Snippets, &exportSnippet{Lang: snippet.Lang, Code: snippet.Code})
+	}
+
+	var err error
+	r.Request, err = exportTree(route.RequestBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "request")
+	}
+	if route.RequestBody != nil {
+		r.RequestExample, err = json.Marshal(route.RequestExample)
+		if err != nil {
+			return nil, errors.Wrap(err, "request example")
+		}
+	}
+	for statusCode, example := range route.ResponseExamples {
+		if r.ResponseExamples == nil {
+			r.ResponseExamples = make(map[int]json.RawMessage, len(route.ResponseExamples))
+		}
+		r.ResponseExamples[statusCode], err = json.Marshal(example)
+		if err != nil {
+			return nil, errors.Wrapf(err, "response example %v", statusCode)
+		}
+	}
+
+	for statusCode, body := range route.ResponseBodies {
+		r.Responses[statusCode], err = exportTree(body)
+		if err != nil {
+			return nil, errors.Wrapf(err, "response %v", statusCode)
+		}
+	}
+
+	return r, nil
+}
+
+func importDocRoute(r *exportRoute) (*DocRoute, error) {
+	route := &DocRoute{
+		Name:           r.Name,
+		Method:         r.Method,
+		Path:           r.Path,
+		Description:    r.Description,
+		Params:         importValues(r.Params),
+		Query:          importValues(r.Query),
+		ResponseBodies: make(map[int]interface{}, len(r.Responses)),
+	}
+	for _, snippet := range r.Snippets {
+		route.Snippets = append(route.Snippets, &DocSnippet{Lang: snippet.Lang, Code: snippet.Code})
+	}
+
+	var err error
+	route.RequestBody, err = importTree(r.Request)
+	if err != nil {
+		return nil, errors.Wrap(err, "request")
+	}
+	route.RequestExample, err = importExample(r.RequestExample)
+	if err != nil {
+		return nil, errors.Wrap(err, "request example")
+	}
+	for statusCode, raw := range r.ResponseExamples {
+		if route.ResponseExamples == nil {
+			route.ResponseExamples = make(map[int]interface{}, len(r.ResponseExamples))
+		}
+		route.ResponseExamples[statusCode], err = importExample(raw)
+		if err != nil {
+			return nil, errors.Wrapf(err, "response example %v", statusCode)
+		}
+	}
+
+	for statusCode, node := range r.Responses {
+		route.ResponseBodies[statusCode], err = importTree(node)
+		if err != nil {
+			return nil, errors.Wrapf(err, "response %v", statusCode)
+		}
+	}
+
+	return route, nil
+}
+
+func exportDocEvent(event *DocEvent) (*exportEvent, error) {
+	e := &exportEvent{
+		Name:        event.Name,
+		Direction:   event.Direction,
+		EventName:   event.EventName,
+		Description: event.Description,
+		Headers:     exportValues(event.Headers),
+	}
+	if event.Channel != nil {
+		e.Channel = &exportChannel{Namespace: event.Channel.Namespace, Name: event.Channel.Name}
+	}
+
+	var err error
+	e.Payload, err = exportTree(event.Payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "payload")
+	}
+	if event.Payload != nil {
+		e.PayloadExample, err = json.Marshal(event.PayloadExample)
+		if err != nil {
+			return nil, errors.Wrap(err, "payload example")
+		}
+	}
+
+	return e, nil
+}
+
+func importDocEvent(e *exportEvent) (*DocEvent, error) {
+	event := &DocEvent{
+		Name:        e.Name,
+		Direction:   e.Direction,
+		EventName:   e.EventName,
+		Description: e.Description,
+		Headers:     importValues(e.Headers),
+	}
+	if e.Channel != nil {
+		event.Channel = &DocChannel{Namespace: e.Channel.Namespace, Name: e.Channel.Name}
+	}
+
+	var err error
+	event.Payload, err = importTree(e.Payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "payload")
+	}
+	event.PayloadExample, err = importExample(e.PayloadExample)
+	if err != nil {
+		return nil, errors.Wrap(err, "payload example")
+	}
+
+	return event, nil
+}
+
+// upgradeEventRoutes replaces the routes of the event categories of version 1 documents with events. The events
+// were documented as routes under the /(geb-in), /(geb-out) and /(centrifuge) prefixes, with their payloads in the
+// request, or in the response with status code 0 for the fired GEB events.
+func upgradeEventRoutes(doc *Document) {
+	for _, category := range doc.Categories {
+		direction := EventFired
+		switch category.Name {
+		case categoryConsumedMessages:
+			direction = EventConsumed
+		case categoryFiredEvents, categoryCentrifuge:
+		default:
+			continue
+		}
+
+		for _, group := range category.Groups {
+			group.Events = make([]*DocEvent, 0, len(group.Routes))
+			for _, route := range group.Routes {
+				event := &DocEvent{
+					Name:           route.Name,
+					Direction:      direction,
+					Description:    route.Description,
+					Headers:        make(map[string]*DocValue),
+					Payload:        route.RequestBody,
+					PayloadExample: route.RequestExample,
+				}
+				if category.Name == categoryFiredEvents {
+					event.Payload = route.ResponseBodies[0]
+					event.PayloadExample = route.ResponseExamples[0]
+				}
+
+				prefix := group.Prefix
+				for _, fake := range []string{"/(geb-in)", "/(geb-out)", "/(centrifuge)"} {
+					prefix = strings.TrimPrefix(prefix, fake)
+				}
+				event.EventName = prefix + route.Path
+				if category.Name == categoryCentrifuge {
+					parts := strings.SplitN(event.EventName, ":", 2)
+					if len(parts) == 2 {
+						event.Channel = &DocChannel{Namespace: parts[0], Name: parts[1]}
+					}
+				}
+
+				group.Events = append(group.Events, event)
+			}
+			group.Prefix = ""
+			group.Routes = make([]*DocRoute, 0)
+		}
+	}
+}
+
+// importExample decodes an example, with the numbers as json.Number like in the collected examples.
+func importExample(raw json.RawMessage) (interface{}, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var example interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	err := decoder.Decode(&example)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return example, nil
+}
+
+func exportValues(values map[string]*DocValue) map[string]*exportValue {
+	result := make(map[string]*exportValue, len(values))
+	for k, v := range values {
+		result[k] = exportDocValue(v)
+	}
+
+	return result
+}
+
+func importValues(values map[string]*exportValue) map[string]*DocValue {
+	result := make(map[string]*DocValue, len(values))
+	for k, v := range values {
+		result[k] = importDocValue(v)
+	}
+
+	return result
+}
+
+func exportDocValue(v *DocValue) *exportValue {
+	return &exportValue{
+		Value:    v.Value,
+		Desc:     v.Desc,
+		Opt:      v.Opt,
+		Nullable: v.Nullable,
+		Null:     v.Null,
+		Type:     v.APIMDType,
+		Enum:     v.Enum,
+	}
+}
+
+func importDocValue(v *exportValue) *DocValue {
+	return &DocValue{
+		Value:     v.Value,
+		Desc:      v.Desc,
+		Opt:       v.Opt,
+		Nullable:  v.Nullable,
+		Null:      v.Null,
+		APIMDType: v.Type,
+		Enum:      v.Enum,
+	}
+}
+
+func exportTree(tree interface{}) (*exportNode, error) {
+	if tree == nil {
+		return nil, nil
+	}
+
+	switch t := tree.(type) {
+	case map[string]interface{}:
+		object := make(map[string]*exportNode, len(t))
+		for k, v := range t {
+			node, err := exportTree(v)
+			if err != nil {
+				return nil, err
+			}
+			object[k] = node
+		}
+		return &exportNode{Object: object}, nil
+
+	case *DocArray:
+		item, err := exportTree(t.Item)
+		if err != nil {
+			return nil, err
+		}
+		return &exportNode{Array: &exportArray{
+			Item:        item,
+			MinItems:    t.MinItems,
+			MaxItems:    t.MaxItems,
+			UniqueItems: t.UniqueItems,
+		}}, nil
+
+	case *DocOneOf:
+		variants := make([]*exportNode, 0, len(t.Variants))
+		for _, v := range t.Variants {
+			node, err := exportTree(v)
+			if err != nil {
+				return nil, err
+			}
+			variants = append(variants, node)
+		}
+		return &exportNode{OneOf: &exportOneOf{Discriminator: t.Discriminator, Variants: variants}}, nil
+
+	case *DocRef:
+		return &exportNode{Ref: t.Name}, nil
+
+	case *DocValue:
+		return &exportNode{Value: exportDocValue(t)}, nil
+
+	default:
+		return nil, errors.Errorf("invalid type %T in document tree", tree)
+	}
+}
+
+func importTree(node *exportNode) (interface{}, error) {
+	if node == nil {
+		return nil, nil
+	}
+
+	switch {
+	case node.Object != nil:
+		object := make(map[string]interface{}, len(node.Object))
+		for k, v := range node.Object {
+			child, err := importTree(v)
+			if err != nil {
+				return nil, err
+			}
+			object[k] = child
+		}
+		return object, nil
+
+	case node.Array != nil:
+		item, err := importTree(node.Array.Item)
+		if err != nil {
+			return nil, err
+		}
+		return &DocArray{
+			Item:        item,
+			MinItems:    node.Array.MinItems,
+			MaxItems:    node.Array.MaxItems,
+			UniqueItems: node.Array.UniqueItems,
+		}, nil
+
+	case node.OneOf != nil:
+		oneOf := &DocOneOf{
+			Discriminator: node.OneOf.Discriminator,
+			Variants:      make([]interface{}, 0, len(node.OneOf.Variants)),
+		}
+		for _, v := range node.OneOf.Variants {
+			variant, err := importTree(v)
+			if err != nil {
+				return nil, err
+			}
+			oneOf.Variants = append(oneOf.Variants, variant)
+		}
+		return oneOf, nil
+
+	case node.Ref != "":
+		return &DocRef{Name: node.Ref}, nil
+
+	case node.Value != nil:
+		return importDocValue(node.Value), nil
+
+	default:
+		// the Object of empty objects is omitted
+		return map[string]interface{}{}, nil
+	}
+}
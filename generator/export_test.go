@@ -0,0 +1,200 @@
+package generator
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// testRoundTripDocument returns a collected document with every kind of node, for the round trips of the JSON export
+// and of the API.md parser.
+func testRoundTripDocument(t *testing.T) *Document {
+	type user struct {
+		ID        string   `json:"id"`
+		Tags      []string `json:"tags"`
+		DeletedAt *string  `json:"deleted_at"`
+	}
+	type request struct {
+		ID    string `param:"id"`
+		Limit int    `query:"limit"`
+		User  user   `json:"user"`
+	}
+	type headers struct {
+		TraceID string `json:"trace_id"`
+	}
+
+	c := newCollector()
+	c.dataStructures = true
+	doc := mustCollect(t, c, &testDefinitions{groups: func(f *Factory) []Group {
+		limit := f.Query("10")
+		limit.Optional()
+		limit.Description("page size")
+		limit.Enum("10", "50")
+		tag := f.Body("admin")
+		tag.MinItems(1)
+		tag.UniqueItems()
+		tag.Enum("admin", "user")
+
+		return []Group{
+			&HTTPGroup{
+				Name: "Users",
+				Routes: []*HTTPRoute{
+					{
+						Name:        "Update",
+						Method:      http.MethodPut,
+						Path:        "/users/:id",
+						Description: []string{"Updates a user.", "Second line."},
+						Request: request{
+							ID:    f.Param("1").String(),
+							Limit: limit.Int(),
+							User:  user{ID: f.Body("1").String(), Tags: []string{tag.String()}},
+						},
+						Responses: map[int]interface{}{
+							http.StatusOK:       user{ID: f.Body("1").String(), DeletedAt: f.Null().StringPtr()},
+							http.StatusNotFound: nil,
+						},
+					},
+					{
+						Name:   "Pay",
+						Method: http.MethodPost,
+						Path:   "/pay",
+						Request: &OneOf{Discriminator: "id", Variants: []interface{}{
+							user{ID: f.Body("card").String()},
+							user{ID: f.Body("bank").String(), Tags: []string{f.Body("x").String()}},
+						}},
+						Responses: map[int]interface{}{http.StatusAccepted: f.Body("accepted").String()},
+					},
+					{
+						Name:   "Import",
+						Method: http.MethodPost,
+						Path:   "/import",
+						Request: &OneOf{Variants: []interface{}{
+							user{ID: f.Body("2").String()},
+							[]string{f.Body("y").String()},
+						}},
+					},
+				},
+			},
+			&FiredEventsGroup{
+				Name:        "Events",
+				RoutePrefix: "/event",
+				Events: []*GEBEvent{{
+					Name:        "Updated",
+					EventName:   "/user/updated/v1",
+					Description: []string{"Fired on updates.", "Second line."},
+					Headers:     headers{TraceID: f.Header("t1").String()},
+					Body:        user{ID: f.Body("1").String()},
+				}},
+			},
+			&ConsumedMessagesGroup{
+				Name:   "Messages",
+				Events: []*GEBEvent{{Name: "Deleted", EventName: "/user/deleted/v1"}},
+			},
+			&CentrifugeGroup{
+				Name: "Centrifuge",
+				Events: []*CentrifugeEvent{{
+					Name:      "Online",
+					Namespace: "users",
+					Channel:   "online",
+					Headers:   headers{TraceID: f.Header("t2").String()},
+					Params:    f.Body("ada").String(),
+				}},
+			},
+		}
+	}})
+
+	err := addSnippets(doc, "http://localhost", []string{SnippetCurl, SnippetHTTPie})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	return doc
+}
+
+func TestMarshalDocument(t *testing.T) {
+	doc := testRoundTripDocument(t)
+
+	b, err := MarshalDocument(doc)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	got, err := UnmarshalDocument(b)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if !reflect.DeepEqual(doc, got) {
+		b2, _ := MarshalDocument(got)
+		t.Errorf("round trip mismatch, want:\n%s\ngot:\n%s", b, b2)
+	}
+
+	empty := map[string]interface{}{"meta": map[string]interface{}{}}
+	b, err = MarshalDocument(&Document{DataStructures: []*DocDataStructure{{Name: "Empty", Value: empty}}})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	got, err = UnmarshalDocument(b)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if len(got.DataStructures) != 1 || !reflect.DeepEqual(got.DataStructures[0].Value, empty) {
+		t.Errorf("round trip of empty objects, got: %s", b)
+	}
+}
+
+func TestUnmarshalDocumentVersion(t *testing.T) {
+	_, err := UnmarshalDocument([]byte(`{"version": 99, "document": {}}`))
+	if err == nil {
+		t.Errorf("want error for unsupported version")
+	}
+
+	doc, err := UnmarshalDocument([]byte(`{"version": 1, "document": {"categories": [
+		{"name": "Consumed GEB Messages", "groups": [{"name": "Messages", "prefix": "/(geb-in)/msg", "routes": [
+			{"name": "Deleted", "method": "POST", "path": "/user/deleted/v1", "request": {"value": {"value": "1", "type": "string"}}, "responses": {"0": null}}
+		]}]},
+		{"name": "Fired GEB Events", "groups": [{"name": "Events", "prefix": "/(geb-out)", "routes": [
+			{"name": "Created", "method": "GET", "path": "/user/created/v1", "responses": {"0": {"value": {"value": "2", "type": "string"}}}}
+		]}]},
+		{"name": "Fired Centrifuge Events", "groups": [{"name": "Centrifuge", "prefix": "/(centrifuge)", "routes": [
+			{"name": "Online", "method": "POST", "path": "users:online", "responses": {"0": null}}
+		]}]}
+	]}}`))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	want := []*DocEvent{
+		{
+			Name:           "Deleted",
+			Direction:      EventConsumed,
+			EventName:      "/msg/user/deleted/v1",
+			Headers:        map[string]*DocValue{},
+			Payload:        &DocValue{Value: "1", APIMDType: "string"},
+			PayloadExample: "1",
+		},
+		{
+			Name:           "Created",
+			Direction:      EventFired,
+			EventName:      "/user/created/v1",
+			Headers:        map[string]*DocValue{},
+			Payload:        &DocValue{Value: "2", APIMDType: "string"},
+			PayloadExample: "2",
+		},
+		{
+			Name:      "Online",
+			Direction: EventFired,
+			EventName: "users:online",
+			Channel:   &DocChannel{Namespace: "users", Name: "online"},
+			Headers:   map[string]*DocValue{},
+		},
+	}
+	for i, category := range doc.Categories {
+		group := category.Groups[0]
+		if group.Prefix != "" || len(group.Routes) != 0 || len(group.Events) != 1 {
+			t.Fatalf("%v: want a single event, got %+v", category.Name, group)
+		}
+		if !reflect.DeepEqual(group.Events[0], want[i]) {
+			t.Errorf("%v: got %+v, want %+v", category.Name, group.Events[0], want[i])
+		}
+	}
+}
@@ -17,13 +17,19 @@ type Marker struct {
 }
 
 type Value struct {
-	index     int
-	value     string
-	desc      string
-	typ       string
-	opt       bool
-	apimdType string
-	factory   *Factory
+	index       int
+	value       string
+	desc        string
+	typ         string
+	opt         bool
+	nullable    bool
+	null        bool
+	apimdType   string
+	minItems    int
+	maxItems    int
+	uniqueItems bool
+	enum        []string
+	factory     *Factory
 }
 
 func (v *Value) MarshalJSON() ([]byte, error) {
@@ -49,6 +55,21 @@ func (f *Factory) Body(val string) *Value {
 	return f.newValue(val, typeBody)
 }
 
+// Header returns a placeholder of an event header, see: GEBEvent.Headers
+func (f *Factory) Header(val string) *Value {
+	return f.newValue(val, typeHeader)
+}
+
+// Null returns a body placeholder, which is documented as an explicit null value.
+// The type of the value is still taken from the conversion used, eg. Null().String() is a nullable string.
+func (f *Factory) Null() *Value {
+	v := f.newValue("", typeBody)
+	v.nullable = true
+	v.null = true
+
+	return v
+}
+
 func (f *Factory) Marker(v *Value, jsonPlaceholder interface{}) bool {
 	key := fmt.Sprintf("%s%s%v", v.value, v.desc, v.opt)
 
@@ -84,7 +105,10 @@ func (v *Value) docValue() *DocValue {
 		Value:     v.value,
 		Desc:      v.desc,
 		Opt:       v.opt,
+		Nullable:  v.nullable,
+		Null:      v.null,
 		APIMDType: v.apimdType,
+		Enum:      v.enum,
 	}
 }
 
@@ -104,6 +128,33 @@ func (v *Value) Optional() {
 	v.opt = true
 }
 
+func (v *Value) Nullable() {
+	v.nullable = true
+}
+
+// Enum documents the possible values of the value, eg. f.Body("active").Enum("active", "deleted").
+func (v *Value) Enum(values ...string) {
+	v.enum = values
+}
+
+// MinItems sets the minimum number of elements of the array, which directly contains the value,
+// or the object of the value.
+func (v *Value) MinItems(n int) {
+	v.minItems = n
+}
+
+// MaxItems sets the maximum number of elements of the array, which directly contains the value,
+// or the object of the value.
+func (v *Value) MaxItems(n int) {
+	v.maxItems = n
+}
+
+// UniqueItems documents that the elements of the array, which directly contains the value,
+// or the object of the value, are unique.
+func (v *Value) UniqueItems() {
+	v.uniqueItems = true
+}
+
 func (v *Value) String() string {
 	return strconv.Itoa(v.Index())
 }
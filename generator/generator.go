@@ -9,68 +9,210 @@ import (
 	"strconv"
 	"strings"
 	"text/template"
+
+	"github.com/pkg/errors"
 )
 
-type Generator struct{}
+type Generator struct {
+	dataStructures bool
+	zeroValues     bool
+	jsonExportPath string
+	lintConfig     LintConfig
+	snippetsURL    string
+	snippetTools   []string
+}
+
+type Option func(g *Generator)
+
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+	for _, option := range options {
+		option(g)
+	}
 
-func NewGenerator() *Generator {
-	return &Generator{}
+	return g
 }
 
-func (g *Generator) Generate(d Definitons) {
+// WithDataStructures moves the objects which occur more than once in the bodies to a Data Structures section.
+func WithDataStructures() Option {
+	return func(g *Generator) {
+		g.dataStructures = true
+	}
+}
+
+// WithZeroValues documents the zero values which are set explicitly by the definitions: map and slice elements,
+// and values set through pointers, eg. `Count: &zero`. Struct fields left at their zero value are still omitted.
+func WithZeroValues() Option {
+	return func(g *Generator) {
+		g.zeroValues = true
+	}
+}
+
+// WithJSONExport writes the document to path as JSON as well, see: MarshalDocument
+func WithJSONExport(path string) Option {
+	return func(g *Generator) {
+		g.jsonExportPath = path
+	}
+}
+
+// WithLintConfig sets the severity of the lint rules for the lint command, see: Main
+func WithLintConfig(config LintConfig) Option {
+	return func(g *Generator) {
+		g.lintConfig = config
+	}
+}
+
+// WithSnippets adds a ready to run request example of every http route for each of the tools, SnippetCurl by default.
+// The requests are sent to baseURL, with the example values of the params and the body.
+func WithSnippets(baseURL string, tools ...string) Option {
+	return func(g *Generator) {
+		if len(tools) == 0 {
+			tools = []string{SnippetCurl}
+		}
+		g.snippetsURL = baseURL
+		g.snippetTools = tools
+	}
+}
+
+// Collect returns the document of the definitions, as it is used for generating API.md.
+// Like Generate, it exits on invalid definitions, see: CollectDocument
+func (g *Generator) Collect(d Definitons) *Document {
+	doc, err := g.CollectDocument(d)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+	return doc
+}
+
+// CollectDocument returns the document of the definitions, or the error of invalid definitions, eg. when the
+// path params don't match the params of the request.
+func (g *Generator) CollectDocument(d Definitons) (*Document, error) {
 	c := newCollector()
-	doc := c.collect(d)
+	c.dataStructures = g.dataStructures
+	c.zeroValues = g.zeroValues
+
+	doc, err := c.collect(d)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(g.snippetTools) > 0 {
+		err := addSnippets(doc, g.snippetsURL, g.snippetTools)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+func (g *Generator) Generate(d Definitons) {
+	doc := g.Collect(d)
 
+	b, err := RenderAPIMD(doc)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+	apimd, err := filepath.Abs(d.OutputPath())
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+	err = ioutil.WriteFile(apimd, b, 0644)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+	log.Print("Updated " + apimd + ":1")
+
+	if g.jsonExportPath != "" {
+		g.writeJSONExport(doc)
+	}
+}
+
+// RenderAPIMD renders doc as API Blueprint.
+func RenderAPIMD(doc *Document) ([]byte, error) {
 	t, err := template.
 		New("").
-		Funcs(template.FuncMap{
-			"dict": func(v ...interface{}) map[string]interface{} {
-				result := make(map[string]interface{}, len(v)/2)
-				for i := 0; i < len(v)-1; i += 2 {
-					result[fmt.Sprintf("%s", v[i])] = v[i+1]
-				}
-
-				return result
-			},
-			"isValue": func(v interface{}) bool {
-				_, ok := v.(*DocValue)
-				return ok
-			},
-			"isArray": func(v interface{}) bool {
-				_, ok := v.([]interface{})
-				return ok
-			},
-			"add": func(i1 int, i2 int) int {
-				return i1 + i2
-			},
-			"indent": func(i int) string {
-				return strings.Repeat(" ", i)
-			},
-			"dig3": func(i int) string {
-				result := strconv.Itoa(i)
-				return strings.Repeat("0", 3-len(result)) + result
-			},
-		}).
+		Funcs(templateFuncs()).
 		Parse(apimdTmpl)
 	if err != nil {
-		log.Fatalf("%+v", err)
+		return nil, errors.WithStack(err)
 	}
 
 	buf := &bytes.Buffer{}
 	err = t.ExecuteTemplate(buf, "base", doc)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// templateFuncs returns the functions of the document templates, shared by the API.md and the HTML templates.
+func templateFuncs() map[string]interface{} {
+	return map[string]interface{}{
+		"dict": func(v ...interface{}) map[string]interface{} {
+			result := make(map[string]interface{}, len(v)/2)
+			for i := 0; i < len(v)-1; i += 2 {
+				result[fmt.Sprintf("%s", v[i])] = v[i+1]
+			}
+
+			return result
+		},
+		"isValue": func(v interface{}) bool {
+			_, ok := v.(*DocValue)
+			return ok
+		},
+		"isOneOf": func(v interface{}) bool {
+			_, ok := v.(*DocOneOf)
+			return ok
+		},
+		"isRef": func(v interface{}) bool {
+			_, ok := v.(*DocRef)
+			return ok
+		},
+		"isArray": func(v interface{}) bool {
+			_, ok := v.(*DocArray)
+			return ok
+		},
+		"arrayType":        arrayType,
+		"arrayConstraints": arrayConstraints,
+		"add": func(i1 int, i2 int) int {
+			return i1 + i2
+		},
+		"indent": func(i int) string {
+			return strings.Repeat(" ", i)
+		},
+		"dig3": func(i int) string {
+			result := strconv.Itoa(i)
+			return strings.Repeat("0", 3-len(result)) + result
+		},
+		"json":       indentJSON,
+		"eventLabel": eventLabel,
+		"indentLines": func(s string, i int) string {
+			return strings.Repeat(" ", i) + strings.Replace(s, "\n", "\n"+strings.Repeat(" ", i), -1)
+		},
+	}
+}
+
+func (g *Generator) writeJSONExport(doc *Document) {
+	b, err := MarshalDocument(doc)
 	if err != nil {
 		log.Fatalf("%+v", err)
 	}
 
-	apimd, err := filepath.Abs(d.OutputPath())
+	export, err := filepath.Abs(g.jsonExportPath)
 	if err != nil {
 		log.Fatalf("%+v", err)
 	}
 
-	err = ioutil.WriteFile(apimd, buf.Bytes(), 0644)
+	err = ioutil.WriteFile(export, b, 0644)
 	if err != nil {
 		log.Fatalf("%+v", err)
 	}
 
-	log.Print("Updated " + apimd + ":1")
+	log.Print("Updated " + export + ":1")
 }
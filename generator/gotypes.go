@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// goInfo holds what is known about the go values of a tree, by key path.
+type goInfo struct {
+	typeNames map[string]string
+	// explicit values were set by the definitions, rather than being left as the zero value of a struct field
+	explicit map[string]bool
+}
+
+func newGoInfo(data interface{}) *goInfo {
+	info := &goInfo{
+		typeNames: make(map[string]string),
+		explicit:  make(map[string]bool),
+	}
+	walkData("", reflect.ValueOf(data), true, func(path string, v reflect.Value, explicit bool) {
+		t := v.Type()
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct && t.Name() != "" {
+			info.typeNames[path] = t.Name()
+		}
+		if explicit {
+			info.explicit[path] = true
+		}
+	})
+
+	return info
+}
+
+// walkData calls fn for every value reachable from v, using the same key paths as createIndexTree.
+// Struct fields are named after the first of the tagKeys they are tagged with, the same way as encDec names them.
+func walkData(path string, v reflect.Value, explicit bool, fn func(path string, v reflect.Value, explicit bool)) {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return
+	}
+
+	fn(path, v, explicit)
+
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		walkStruct(path, v, fn)
+
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// encoded as a base64 string
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			walkData(path+"."+strconv.Itoa(i), v.Index(i), true, fn)
+		}
+
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			walkData(path+"."+fmt.Sprint(k.Interface()), v.MapIndex(k), true, fn)
+		}
+	}
+}
+
+func walkStruct(path string, v reflect.Value, fn func(path string, v reflect.Value, explicit bool)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+		key, tagged := fieldKey(field)
+
+		if !tagged && field.Anonymous {
+			// untagged embedded structs are flattened
+			for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				walkStruct(path, fv, fn)
+			}
+			continue
+		}
+		if !tagged || field.PkgPath != "" {
+			continue
+		}
+
+		// a zero value can only be told apart from an unset field, if it is set through a pointer or an interface
+		explicit := (fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface) && !fv.IsNil()
+		walkData(path+"."+key, fv, explicit, fn)
+	}
+}
+
+func fieldKey(field reflect.StructField) (string, bool) {
+	for _, tagKey := range tagKeys {
+		tag, ok := field.Tag.Lookup(tagKey)
+		if !ok {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		return name, true
+	}
+
+	return "", false
+}
@@ -1,6 +1,17 @@
 package generator
 
-import "net/http"
+const (
+	categoryHTTP             = "Http"
+	categoryConsumedMessages = "Consumed GEB Messages"
+	categoryFiredEvents      = "Fired GEB Events"
+	categoryCentrifuge       = "Fired Centrifuge Events"
+)
+
+// The directions of the events, see: DocEvent
+const (
+	EventConsumed = "consumed"
+	EventFired    = "fired"
+)
 
 type Group interface {
 	GetName() string
@@ -9,6 +20,23 @@ type Group interface {
 	GetCategory() string
 }
 
+// EventGroup is a group of events, its events are documented instead of its routes.
+type EventGroup interface {
+	Group
+	GetEvents() []*Event
+}
+
+// Event is an event of an EventGroup, with the full name of a GEB event, or the Channel of a Centrifuge event.
+type Event struct {
+	Name        string
+	Direction   string
+	EventName   string
+	Channel     *DocChannel
+	Description []string
+	Headers     interface{}
+	Payload     interface{}
+}
+
 type Route struct {
 	Name        string
 	Method      string
@@ -33,6 +61,13 @@ type HTTPRoute struct {
 	Responses   map[int]interface{}
 }
 
+// OneOf can be used as a request, response or event body which can take the shape of any of its Variants.
+// Discriminator is the name of the property which tells the variants apart, it is optional.
+type OneOf struct {
+	Discriminator string
+	Variants      []interface{}
+}
+
 type ConsumedMessagesGroup struct {
 	Name        string
 	RoutePrefix string
@@ -49,7 +84,9 @@ type GEBEvent struct {
 	Name        string
 	EventName   string
 	Description []string
-	Body        interface{}
+	// Headers is documented like the Body, with Factory.Header values
+	Headers interface{}
+	Body    interface{}
 }
 
 type CentrifugeGroup struct {
@@ -62,7 +99,9 @@ type CentrifugeEvent struct {
 	Namespace   string
 	Channel     string
 	Description []string
-	Params      interface{}
+	// Headers is documented like the Params, with Factory.Header values
+	Headers interface{}
+	Params  interface{}
 }
 
 func (g *HTTPGroup) GetName() string {
@@ -89,7 +128,7 @@ func (g *HTTPGroup) GetRoutes() []*Route {
 }
 
 func (g *HTTPGroup) GetCategory() string {
-	return "Http"
+	return categoryHTTP
 }
 
 func (g *ConsumedMessagesGroup) GetName() string {
@@ -97,27 +136,20 @@ func (g *ConsumedMessagesGroup) GetName() string {
 }
 
 func (g *ConsumedMessagesGroup) GetRoutePrefix() string {
-	return "/(geb-in)" + g.RoutePrefix
+	return g.RoutePrefix
 }
 
 func (g *ConsumedMessagesGroup) GetCategory() string {
-	return "Consumed GEB Messages"
+	return categoryConsumedMessages
 }
 
+// GetRoutes returns no routes, the messages are documented as events, see: GetEvents
 func (g *ConsumedMessagesGroup) GetRoutes() []*Route {
-	result := make([]*Route, 0, len(g.Events))
-	for _, e := range g.Events {
-		result = append(result, &Route{
-			Name:        e.Name,
-			Path:        e.EventName,
-			Method:      http.MethodPost,
-			Description: e.Description,
-			Request:     e.Body,
-			Responses:   map[int]interface{}{0: nil},
-		})
-	}
+	return []*Route{}
+}
 
-	return result
+func (g *ConsumedMessagesGroup) GetEvents() []*Event {
+	return gebEvents(EventConsumed, g.RoutePrefix, g.Events)
 }
 
 func (g *FiredEventsGroup) GetName() string {
@@ -125,22 +157,32 @@ func (g *FiredEventsGroup) GetName() string {
 }
 
 func (g *FiredEventsGroup) GetRoutePrefix() string {
-	return "/(geb-out)" + g.RoutePrefix
+	return g.RoutePrefix
 }
 
 func (g *FiredEventsGroup) GetCategory() string {
-	return "Fired GEB Events"
+	return categoryFiredEvents
 }
 
+// GetRoutes returns no routes, see: GetEvents
 func (g *FiredEventsGroup) GetRoutes() []*Route {
-	result := make([]*Route, 0, len(g.Events))
-	for _, e := range g.Events {
-		result = append(result, &Route{
+	return []*Route{}
+}
+
+func (g *FiredEventsGroup) GetEvents() []*Event {
+	return gebEvents(EventFired, g.RoutePrefix, g.Events)
+}
+
+func gebEvents(direction string, prefix string, events []*GEBEvent) []*Event {
+	result := make([]*Event, 0, len(events))
+	for _, e := range events {
+		result = append(result, &Event{
 			Name:        e.Name,
-			Path:        e.EventName,
-			Method:      http.MethodGet,
+			Direction:   direction,
+			EventName:   prefix + e.EventName,
 			Description: e.Description,
-			Responses:   map[int]interface{}{0: e.Body},
+			Headers:     e.Headers,
+			Payload:     e.Body,
 		})
 	}
 
@@ -152,23 +194,28 @@ func (g *CentrifugeGroup) GetName() string {
 }
 
 func (g *CentrifugeGroup) GetRoutePrefix() string {
-	return "/(centrifuge)"
+	return ""
 }
 
 func (g *CentrifugeGroup) GetCategory() string {
-	return "Fired Centrifuge Events"
+	return categoryCentrifuge
 }
 
+// GetRoutes returns no routes, see: GetEvents
 func (g *CentrifugeGroup) GetRoutes() []*Route {
-	result := make([]*Route, 0, len(g.Events))
+	return []*Route{}
+}
+
+func (g *CentrifugeGroup) GetEvents() []*Event {
+	result := make([]*Event, 0, len(g.Events))
 	for _, e := range g.Events {
-		result = append(result, &Route{
+		result = append(result, &Event{
 			Name:        e.Name,
-			Path:        e.Namespace + "\\:" + e.Channel,
-			Method:      http.MethodPost,
+			Direction:   EventFired,
+			Channel:     &DocChannel{Namespace: e.Namespace, Name: e.Channel},
 			Description: e.Description,
-			Request:     e.Params,
-			Responses:   map[int]interface{}{0: nil},
+			Headers:     e.Headers,
+			Payload:     e.Params,
 		})
 	}
 
@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+const (
+	handlerAPIMDPath   = "/API.md"
+	handlerJSONPath    = "/api.json"
+	handlerOpenAPIPath = "/openapi.json"
+)
+
+// docsHandler serves pre-rendered files by path.
+type docsHandler struct {
+	files map[string]*docsFile
+}
+
+type docsFile struct {
+	contentType string
+	body        []byte
+	etag        string
+}
+
+// NewHandler collects the definitions once, and serves the document of the running binary:
+//
+//	/              HTML, see: RenderHTML
+//	/API.md        API Blueprint, see: RenderAPIMD
+//	/api.json      JSON, see: MarshalDocument
+//	/openapi.json  OpenAPI, see: MarshalOpenAPI
+//
+// The responses have an ETag, and requests with a matching If-None-Match header get 304 Not Modified.
+// Mount it with its prefix stripped, eg.:
+//
+//	mux.Handle("/docs/", http.StripPrefix("/docs", handler))
+func NewHandler(d Definitons, options ...Option) (http.Handler, error) {
+	doc, err := NewGenerator(options...).CollectDocument(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDocsHandler(doc, singleHTMLPage(doc))
+}
+
+func newDocsHandler(doc *Document, page *htmlPage) (*docsHandler, error) {
+	html, err := renderHTML(page)
+	if err != nil {
+		return nil, err
+	}
+
+	apimd, err := RenderAPIMD(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	export, err := MarshalDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	openAPI, err := MarshalOpenAPI(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &docsHandler{files: map[string]*docsFile{
+		"/":                newDocsFile("text/html; charset=utf-8", html),
+		handlerAPIMDPath:   newDocsFile("text/markdown; charset=utf-8", apimd),
+		handlerJSONPath:    newDocsFile("application/json", export),
+		handlerOpenAPIPath: newDocsFile("application/json", openAPI),
+	}}, nil
+}
+
+func newDocsFile(contentType string, body []byte) *docsFile {
+	sum := sha1.Sum(body)
+
+	return &docsFile{
+		contentType: contentType,
+		body:        body,
+		etag:        `"` + hex.EncodeToString(sum[:]) + `"`,
+	}
+}
+
+func (h *docsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	file, ok := h.files[path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("ETag", file.etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), file.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", file.contentType)
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(file.body)
+	}
+}
+
+// etagMatches reports whether the If-None-Match header matches etag, using the weak comparison of RFC 7232.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
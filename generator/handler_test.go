@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewHandler(t *testing.T) {
+	type user struct {
+		ID string `json:"id"`
+	}
+
+	handler, err := NewHandler(&testDefinitions{groups: func(f *Factory) []Group {
+		return []Group{&HTTPGroup{
+			Name: "Users",
+			Routes: []*HTTPRoute{{
+				Name:      "List",
+				Method:    http.MethodGet,
+				Path:      "/users",
+				Responses: map[int]interface{}{http.StatusOK: user{ID: f.Body("1").String()}},
+			}},
+		}}
+	}})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	server := httptest.NewServer(http.StripPrefix("/docs", handler))
+	defer server.Close()
+
+	tests := []struct {
+		path        string
+		contentType string
+		contains    string
+	}{
+		{path: "/docs", contentType: "text/html; charset=utf-8", contains: `id="route-get-users"`},
+		{path: "/docs/", contentType: "text/html; charset=utf-8", contains: `id="route-get-users"`},
+		{path: "/docs/API.md", contentType: "text/markdown; charset=utf-8", contains: "#### List [GET /users]"},
+		{path: "/docs/api.json", contentType: "application/json", contains: `"version": 2`},
+		{path: "/docs/openapi.json", contentType: "application/json", contains: `"openapi": "3.1.0"`},
+	}
+
+	for _, test := range tests {
+		resp, body := get(t, server.URL+test.path, "")
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%v: status %v", test.path, resp.StatusCode)
+			continue
+		}
+		if got := resp.Header.Get("Content-Type"); got != test.contentType {
+			t.Errorf("%v: content type %v, want %v", test.path, got, test.contentType)
+		}
+		if !strings.Contains(body, test.contains) {
+			t.Errorf("%v: missing %q", test.path, test.contains)
+		}
+
+		etag := resp.Header.Get("ETag")
+		if etag == "" {
+			t.Errorf("%v: missing ETag", test.path)
+			continue
+		}
+		resp, body = get(t, server.URL+test.path, `"other", W/`+etag)
+		if resp.StatusCode != http.StatusNotModified || body != "" {
+			t.Errorf("%v: status %v with If-None-Match, want 304 without body", test.path, resp.StatusCode)
+		}
+	}
+
+	_, body := get(t, server.URL+"/docs/openapi.json", "")
+	if err := validateOpenAPI([]byte(body)); err != nil {
+		t.Errorf("invalid OpenAPI document: %v", err)
+	}
+
+	resp, _ := get(t, server.URL+"/docs/swagger.json", "")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status %v for an unknown path, want 404", resp.StatusCode)
+	}
+}
+
+func TestNewHandlerInvalidDefinitions(t *testing.T) {
+	_, err := NewHandler(&testDefinitions{groups: func(f *Factory) []Group {
+		return []Group{&HTTPGroup{
+			Name: "Users",
+			Routes: []*HTTPRoute{{
+				Name:   "Get",
+				Method: http.MethodGet,
+				Path:   "/users/:id",
+			}},
+		}}
+	}})
+	if err == nil || !strings.Contains(err.Error(), "path params do not match") {
+		t.Errorf("want path params error, got: %v", err)
+	}
+}
+
+func get(t *testing.T, url string, ifNoneMatch string) (*http.Response, string) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer resp.Body.Close()
+
+	body := &strings.Builder{}
+	_, err = io.Copy(body, resp.Body)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	return resp, body.String()
+}
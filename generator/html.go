@@ -0,0 +1,140 @@
+package generator
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// htmlPage is the data of the HTML template. The navigation always lists the whole Doc, the page shows the
+// Categories and DataStructures, or the overview of the site for the Index.
+// With LiveReload set, the page polls VersionPath, and reloads when it returns something else than Version.
+type htmlPage struct {
+	Doc            *Document
+	Title          string
+	Heading        string
+	Index          bool
+	Categories     []*DocCategory
+	DataStructures []*DocDataStructure
+	SearchIndex    string
+	LiveReload     bool
+	Version        string
+	VersionPath    string
+
+	// files of the pages of a site, empty for a single page
+	indexFile          string
+	groupFiles         map[*DocGroup]string
+	dataStructuresFile string
+}
+
+// RenderHTML renders doc as a single HTML page, with a sidebar for navigation and search.
+func RenderHTML(doc *Document) ([]byte, error) {
+	return renderHTML(singleHTMLPage(doc))
+}
+
+func singleHTMLPage(doc *Document) *htmlPage {
+	return &htmlPage{
+		Doc:            doc,
+		Title:          doc.Name,
+		Heading:        doc.Name,
+		Categories:     doc.Categories,
+		DataStructures: doc.DataStructures,
+	}
+}
+
+func renderHTML(page *htmlPage) ([]byte, error) {
+	funcs := templateFuncs()
+	funcs["groupAnchor"] = groupAnchor
+	funcs["routeAnchor"] = routeAnchor
+	funcs["eventAnchor"] = eventAnchor
+	funcs["eventSearchText"] = eventSearchText
+	funcs["upper"] = strings.ToUpper
+	funcs["dataStructureAnchor"] = dataStructureAnchor
+	funcs["searchText"] = searchText
+	funcs["indexHref"] = func() string {
+		if page.indexFile == "" {
+			return "#"
+		}
+		return page.indexFile
+	}
+	funcs["groupHref"] = func(group *DocGroup) string {
+		return page.groupFiles[group] + "#" + groupAnchor(group)
+	}
+	funcs["routeHref"] = func(group *DocGroup, route *DocRoute) string {
+		return page.groupFiles[group] + "#" + routeAnchor(group, route)
+	}
+	funcs["eventHref"] = func(group *DocGroup, event *DocEvent) string {
+		return page.groupFiles[group] + "#" + eventAnchor(event)
+	}
+	funcs["dataStructuresHref"] = func() string {
+		return page.dataStructuresFile + "#data-structures"
+	}
+	funcs["dataStructureHref"] = func(name string) string {
+		return page.dataStructuresFile + "#" + dataStructureAnchor(name)
+	}
+
+	t, err := template.
+		New("").
+		Funcs(funcs).
+		Parse(htmlTmpl)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	buf := &bytes.Buffer{}
+	err = t.ExecuteTemplate(buf, "page", page)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func groupAnchor(group *DocGroup) string {
+	return "group-" + slug(group.Name)
+}
+
+// routeAnchor identifies a route within the HTML page, eg. route-get-api-v1-users-id for GET /api/v1/users/{id}.
+func routeAnchor(group *DocGroup, route *DocRoute) string {
+	return "route-" + slug(routeKey(group, route))
+}
+
+// eventAnchor identifies an event within the HTML page, eg. event-fired-event-user-created-v1
+func eventAnchor(event *DocEvent) string {
+	return "event-" + slug(eventKey(event))
+}
+
+func dataStructureAnchor(name string) string {
+	return "ds-" + slug(name)
+}
+
+// searchText is the text the search box matches routes against.
+func searchText(group *DocGroup, route *DocRoute) string {
+	return strings.Join([]string{group.Name, route.Name, routeKey(group, route), strings.Join(route.Description, " ")}, " ")
+}
+
+// eventSearchText is the text the search box matches events against.
+func eventSearchText(group *DocGroup, event *DocEvent) string {
+	return strings.Join([]string{group.Name, event.Name, eventLabel(event), event.EventName, strings.Join(event.Description, " ")}, " ")
+}
+
+func slug(s string) string {
+	b := &strings.Builder{}
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+
+	return b.String()
+}
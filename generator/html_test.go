@@ -0,0 +1,135 @@
+package generator
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderHTML(t *testing.T) {
+	doc := &Document{
+		Name: "Users <API>",
+		Categories: []*DocCategory{{Name: categoryHTTP, Groups: []*DocGroup{{
+			Name:   "Users",
+			Prefix: "/api/v1",
+			Routes: []*DocRoute{{
+				Name:   "Get",
+				Method: http.MethodGet,
+				Path:   "/users/{user_id}",
+				Params: map[string]*DocValue{"user_id": {Value: "1", APIMDType: "string", Desc: "id of the <user>"}},
+				ResponseBodies: map[int]interface{}{http.StatusOK: map[string]interface{}{
+					"friends": &DocArray{Item: &DocRef{Name: "User"}, MinItems: 1},
+					"payment": &DocOneOf{Discriminator: "type", Variants: []interface{}{
+						map[string]interface{}{"type": &DocValue{Value: "card", APIMDType: "string"}},
+					}},
+				}},
+			}},
+		}}}},
+		DataStructures: []*DocDataStructure{{Name: "User", Value: map[string]interface{}{
+			"id": &DocValue{Value: "1", APIMDType: "string"},
+		}}},
+	}
+
+	b, err := RenderHTML(doc)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	html := string(b)
+
+	for _, want := range []string{
+		"<title>Users &lt;API&gt;</title>",
+		`<a href="#route-get-api-v1-users-user-id">`,
+		`<section class="route" id="route-get-api-v1-users-user-id"`,
+		"id of the &lt;user&gt;",
+		`<a href="#ds-user">User</a>`,
+		`<section class="route" id="ds-user"`,
+		"min items: 1",
+		"discriminator: <code>type</code>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing %q from:\n%v", want, html)
+		}
+	}
+	if strings.Contains(html, "setInterval") {
+		t.Errorf("static page should not reload itself")
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"GET /api/v1/users/{id}":     "get-api-v1-users-id",
+		"POST /(centrifuge)ns:ch":    "post-centrifuge-ns-ch",
+		"Consumed Messages":          "consumed-messages",
+		"  --  ":                     "",
+		"PUT /users/{user_id}/items": "put-users-user-id-items",
+	}
+
+	for s, want := range tests {
+		if got := slug(s); got != want {
+			t.Errorf("slug(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestWriteHTMLSite(t *testing.T) {
+	doc := &Document{
+		Name: "Users",
+		Categories: []*DocCategory{
+			{Name: categoryHTTP, Groups: []*DocGroup{{
+				Name: "Users",
+				Routes: []*DocRoute{{
+					Name:           "Create",
+					Method:         http.MethodPost,
+					Path:           "/users",
+					RequestBody:    map[string]interface{}{"user": &DocRef{Name: "User"}},
+					ResponseBodies: map[int]interface{}{http.StatusCreated: nil},
+				}},
+			}}},
+			{Name: categoryFiredEvents, Groups: []*DocGroup{{
+				Name:   "Users",
+				Routes: []*DocRoute{},
+				Events: []*DocEvent{{Name: "Created", Direction: EventFired, EventName: "/user/created"}},
+			}}},
+		},
+		DataStructures: []*DocDataStructure{{Name: "User", Value: map[string]interface{}{
+			"email": &DocValue{Value: "a@b.c", APIMDType: "string"},
+		}}},
+	}
+
+	dir, err := ioutil.TempDir("", "apimd-site")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = WriteHTMLSite(doc, dir)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	files := map[string][]string{
+		"index.html":                  {`href="http-users.html#route-post-users"`, `href="fired-geb-events-users.html#event-fired-user-created"`},
+		"http-users.html":             {`id="route-post-users"`, `<a href="data-structures.html#ds-user">User</a>`, `<script src="search-index.js">`},
+		"fired-geb-events-users.html": {`id="event-fired-user-created"`, "Fired GEB event"},
+		"data-structures.html":        {`id="ds-user"`},
+		"search-index.js":             {`"url":"http-users.html#route-post-users"`, "email"},
+	}
+	for file, wants := range files {
+		b, err := ioutil.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Errorf("%+v", err)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("%v: missing %q", file, want)
+			}
+		}
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(dir, "fired-geb-events-users.html")); strings.Contains(string(b), `id="route-post-users"`) {
+		t.Errorf("group page should only contain its own routes")
+	}
+}
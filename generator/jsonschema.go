@@ -0,0 +1,262 @@
+package generator
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchemaDefs is the prefix of the references to the data structures in $defs.
+const jsonSchemaDefs = "#/$defs/"
+
+const (
+	BodyRequest  = "request"
+	BodyResponse = "response"
+	BodyEvent    = "event"
+)
+
+// BodySchema is the JSON Schema of a request body, a response body or the payload of an event.
+type BodySchema struct {
+	Category *DocCategory
+	Group    *DocGroup
+	Route    *DocRoute
+	// Event is set for BodyEvent, instead of Route
+	Event *DocEvent
+	// Body is BodyRequest, BodyResponse or BodyEvent
+	Body string
+	// StatusCode is set for BodyResponse
+	StatusCode int
+	// File is a unique file name of the schema, eg. users-update.response.200.schema.json
+	File   string
+	Schema map[string]interface{}
+}
+
+// JSONSchemas returns a JSON Schema (draft 2020-12) of every request and response body of the http routes, and of
+// every GEB and Centrifuge payload. The optional values are left out from required, and the data structures are
+// added to $defs.
+func JSONSchemas(doc *Document) []*BodySchema {
+	result := make([]*BodySchema, 0)
+	used := make(map[string]bool)
+	forEachRoute(doc, func(category *DocCategory, group *DocGroup, route *DocRoute) {
+		name := slug(group.Name + " " + route.Name)
+		for i := 2; used[name]; i++ {
+			name = slug(group.Name+" "+route.Name) + "-" + strconv.Itoa(i)
+		}
+		used[name] = true
+
+		add := func(body string, statusCode int, tree interface{}) {
+			file, title := name+"."+body, route.Name+" "+body
+			if body == BodyResponse {
+				file += "." + strconv.Itoa(statusCode)
+				title += " " + strconv.Itoa(statusCode)
+			}
+			result = append(result, &BodySchema{
+				Category:   category,
+				Group:      group,
+				Route:      route,
+				Body:       body,
+				StatusCode: statusCode,
+				File:       file + ".schema.json",
+				Schema:     bodySchema(doc, title, tree),
+			})
+		}
+
+		if route.RequestBody != nil {
+			add(BodyRequest, 0, route.RequestBody)
+		}
+		for _, statusCode := range sortedStatusCodes(route.ResponseBodies) {
+			if body := route.ResponseBodies[statusCode]; body != nil {
+				add(BodyResponse, statusCode, body)
+			}
+		}
+	})
+	forEachEvent(doc, func(category *DocCategory, group *DocGroup, event *DocEvent) {
+		if event.Payload == nil {
+			return
+		}
+		name := slug(group.Name + " " + event.Name)
+		for i := 2; used[name]; i++ {
+			name = slug(group.Name+" "+event.Name) + "-" + strconv.Itoa(i)
+		}
+		used[name] = true
+
+		result = append(result, &BodySchema{
+			Category: category,
+			Group:    group,
+			Event:    event,
+			Body:     BodyEvent,
+			File:     name + "." + BodyEvent + ".schema.json",
+			Schema:   bodySchema(doc, event.Name+" "+BodyEvent, event.Payload),
+		})
+	})
+
+	return result
+}
+
+// WriteJSONSchemas writes the JSONSchemas of doc to dir, a file for each.
+func WriteJSONSchemas(doc *Document, dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, schema := range JSONSchemas(doc) {
+		b, err := json.MarshalIndent(schema.Schema, "", "  ")
+		if err != nil {
+			return errors.Wrapf(err, "encoding %v", schema.File)
+		}
+
+		err = ioutil.WriteFile(filepath.Join(dir, schema.File), append(b, '\n'), 0644)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+// bodySchema returns the root schema of a body, with the data structures it refers to in $defs.
+func bodySchema(doc *Document, title string, tree interface{}) map[string]interface{} {
+	refs := make(map[string]bool)
+	result := jsonSchema(doc, tree, jsonSchemaDefs, refs)
+	result["$schema"] = jsonSchemaDialect
+	result["title"] = title
+
+	if defs := schemaDefinitions(doc, jsonSchemaDefs, refs); len(defs) > 0 {
+		result["$defs"] = defs
+	}
+
+	return result
+}
+
+// schemaDefinitions returns the schemas of the data structures in refs, and of the ones they refer to.
+func schemaDefinitions(doc *Document, refPrefix string, refs map[string]bool) map[string]interface{} {
+	defs := make(map[string]interface{})
+	for len(defs) < len(refs) {
+		names := make([]string, 0, len(refs))
+		for name := range refs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if _, ok := defs[name]; ok {
+				continue
+			}
+			defs[name] = map[string]interface{}{}
+			for _, ds := range doc.DataStructures {
+				if ds.Name == name {
+					defs[name] = jsonSchema(doc, ds.Value, refPrefix, refs)
+				}
+			}
+		}
+	}
+
+	return defs
+}
+
+// jsonSchema returns the schema of a document tree, referring to the data structures by refPrefix and their name,
+// and adds the names of the referred data structures to refs.
+func jsonSchema(doc *Document, tree interface{}, refPrefix string, refs map[string]bool) map[string]interface{} {
+	switch t := tree.(type) {
+	case *DocValue:
+		result := make(map[string]interface{})
+		typ := jsonSchemaType(t.APIMDType)
+		if typ != "" && (t.Nullable || t.Null) {
+			result["type"] = []string{typ, "null"}
+		} else if typ != "" {
+			result["type"] = typ
+		}
+		if t.APIMDType == "array" {
+			result["items"] = map[string]interface{}{"type": "string"}
+		}
+		if t.Desc != "" {
+			result["description"] = t.Desc
+		}
+		if len(t.Enum) > 0 {
+			enum := make([]interface{}, 0, len(t.Enum)+1)
+			for _, member := range t.Enum {
+				enum = append(enum, exampleValue(doc, &DocValue{Value: member, APIMDType: t.APIMDType}))
+			}
+			if t.Nullable || t.Null {
+				enum = append(enum, nil)
+			}
+			if t.APIMDType == "array" {
+				result["items"].(map[string]interface{})["enum"] = enum
+			} else {
+				result["enum"] = enum
+			}
+		}
+		if !t.Null && t.APIMDType != "array" {
+			result["examples"] = []interface{}{exampleValue(doc, t)}
+		}
+		return result
+
+	case *DocArray:
+		result := map[string]interface{}{"type": "array"}
+		if t.Item != nil {
+			result["items"] = jsonSchema(doc, t.Item, refPrefix, refs)
+		}
+		if t.MinItems > 0 {
+			result["minItems"] = t.MinItems
+		}
+		if t.MaxItems > 0 {
+			result["maxItems"] = t.MaxItems
+		}
+		if t.UniqueItems {
+			result["uniqueItems"] = true
+		}
+		return result
+
+	case *DocOneOf:
+		variants := make([]interface{}, 0, len(t.Variants))
+		for _, variant := range t.Variants {
+			variants = append(variants, jsonSchema(doc, variant, refPrefix, refs))
+		}
+		result := map[string]interface{}{"oneOf": variants}
+		if t.Discriminator != "" {
+			result["discriminator"] = map[string]interface{}{"propertyName": t.Discriminator}
+		}
+		return result
+
+	case *DocRef:
+		refs[t.Name] = true
+		return map[string]interface{}{"$ref": refPrefix + t.Name}
+
+	case map[string]interface{}:
+		properties := make(map[string]interface{}, len(t))
+		required := make([]string, 0, len(t))
+		for key, value := range t {
+			properties[key] = jsonSchema(doc, value, refPrefix, refs)
+			if v, ok := value.(*DocValue); !ok || !v.Opt {
+				required = append(required, key)
+			}
+		}
+		sort.Strings(required)
+
+		result := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			result["required"] = required
+		}
+		return result
+
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func jsonSchemaType(apimdType string) string {
+	switch apimdType {
+	case "string", "number", "boolean", "array":
+		return apimdType
+	default:
+		return ""
+	}
+}
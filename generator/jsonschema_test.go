@@ -0,0 +1,129 @@
+package generator
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJSONSchemas(t *testing.T) {
+	doc := &Document{
+		Categories: []*DocCategory{
+			{Name: categoryHTTP, Groups: []*DocGroup{{
+				Name:   "Users",
+				Prefix: "/api",
+				Routes: []*DocRoute{{
+					Name:   "Update",
+					Method: http.MethodPut,
+					Path:   "/users/{id}",
+					Params: map[string]*DocValue{"id": {Value: "1", APIMDType: "string"}},
+					RequestBody: map[string]interface{}{
+						"user":  &DocRef{Name: "User"},
+						"admin": &DocValue{Value: "true", APIMDType: "boolean"},
+					},
+					ResponseBodies: map[int]interface{}{
+						http.StatusOK:       &DocArray{Item: &DocRef{Name: "User"}},
+						http.StatusNotFound: nil,
+					},
+				}},
+			}}},
+			{Name: categoryFiredEvents, Groups: []*DocGroup{{
+				Name: "Events",
+				Events: []*DocEvent{{
+					Name:      "Created",
+					Direction: EventFired,
+					EventName: "/user/created",
+					Payload:   &DocValue{Value: "1", APIMDType: "string", Opt: true},
+				}},
+			}}},
+		},
+		DataStructures: []*DocDataStructure{{Name: "User", Value: map[string]interface{}{
+			"age":        &DocValue{Value: "30", APIMDType: "number", Enum: []string{"30", "40"}},
+			"deleted_at": &DocValue{Null: true, Nullable: true, APIMDType: "string"},
+		}}},
+	}
+
+	schemas := JSONSchemas(doc)
+
+	files := make([]string, 0, len(schemas))
+	for _, schema := range schemas {
+		files = append(files, schema.File)
+	}
+	if got, want := mustJSON(files), `["users-update.request.schema.json","users-update.response.200.schema.json","events-created.event.schema.json"]`; got != want {
+		t.Fatalf("got files %v, want %v", got, want)
+	}
+
+	user := `{"properties":{` +
+		`"age":{"enum":[30,40],"examples":[30],"type":"number"},` +
+		`"deleted_at":{"type":["string","null"]}},` +
+		`"required":["age","deleted_at"],"type":"object"}`
+	for i, want := range []string{
+		`{"$defs":{"User":` + user + `},"$schema":"https://json-schema.org/draft/2020-12/schema",` +
+			`"properties":{"admin":{"examples":[true],"type":"boolean"},"user":{"$ref":"#/$defs/User"}},` +
+			`"required":["admin","user"],"title":"Update request","type":"object"}`,
+		`{"$defs":{"User":` + user + `},"$schema":"https://json-schema.org/draft/2020-12/schema",` +
+			`"items":{"$ref":"#/$defs/User"},"title":"Update response 200","type":"array"}`,
+		`{"$schema":"https://json-schema.org/draft/2020-12/schema","examples":["1"],"title":"Created event","type":"string"}`,
+	} {
+		if got := mustJSON(schemas[i].Schema); got != want {
+			t.Errorf("%v:\ngot:  %v\nwant: %v", schemas[i].File, got, want)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "apimd-schemas")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = WriteJSONSchemas(doc, dir)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "users-update.request.schema.json"))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(b, &schema); err != nil || schema["title"] != "Update request" {
+		t.Errorf("invalid schema file: %s", b)
+	}
+}
+
+func TestJSONSchemaValue(t *testing.T) {
+	for _, data := range []struct {
+		Tree interface{}
+		Want string
+	}{
+		{
+			Tree: &DocValue{Value: "a", APIMDType: "string", Desc: "status", Nullable: true, Enum: []string{"a", "b"}},
+			Want: `{"description":"status","enum":["a","b",null],"examples":["a"],"type":["string","null"]}`,
+		},
+		{
+			Tree: &DocValue{Value: "a,b", APIMDType: "array", Enum: []string{"a", "b"}},
+			Want: `{"items":{"enum":["a","b"],"type":"string"},"type":"array"}`,
+		},
+		{
+			Tree: &DocOneOf{Variants: []interface{}{
+				map[string]interface{}{"card": &DocValue{Value: "4111", APIMDType: "string"}},
+				&DocValue{Value: "1", APIMDType: "number"},
+			}},
+			Want: `{"oneOf":[{"properties":{"card":{"examples":["4111"],"type":"string"}},"required":["card"],"type":"object"},` +
+				`{"examples":[1],"type":"number"}]}`,
+		},
+		{
+			Tree: &DocOneOf{Discriminator: "type", Variants: []interface{}{
+				map[string]interface{}{"type": &DocValue{Value: "card", APIMDType: "string"}},
+			}},
+			Want: `{"discriminator":{"propertyName":"type"},` +
+				`"oneOf":[{"properties":{"type":{"examples":["card"],"type":"string"}},"required":["type"],"type":"object"}]}`,
+		},
+	} {
+		if got := mustJSON(jsonSchema(&Document{}, data.Tree, jsonSchemaDefs, map[string]bool{})); got != data.Want {
+			t.Errorf("got:  %v\nwant: %v", got, data.Want)
+		}
+	}
+}
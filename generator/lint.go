@@ -0,0 +1,185 @@
+package generator
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	LintOff   = "off"
+	LintWarn  = "warn"
+	LintError = "error"
+)
+
+// LintConfig sets the severity of lint rules by name, rules which are not set use their default severity.
+type LintConfig map[string]string
+
+// LintFinding is a single problem found by a lint rule.
+// Group and Route point at the route or event, Field points at the param or field within it.
+type LintFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Group    string `json:"group"`
+	Route    string `json:"route"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+}
+
+type LintRule struct {
+	Name        string
+	Description string
+	Severity    string
+	check       func(doc *Document, report func(group *DocGroup, route string, field string, message string))
+}
+
+// LintRules are the available lint rules, with their default severity.
+var LintRules = []*LintRule{
+	{
+		Name:        "route-description",
+		Description: "routes and events should have a description",
+		Severity:    LintWarn,
+		check: func(doc *Document, report func(*DocGroup, string, string, string)) {
+			forEachRoute(doc, func(category *DocCategory, group *DocGroup, route *DocRoute) {
+				if strings.TrimSpace(strings.Join(route.Description, "")) == "" {
+					report(group, routeKey(group, route), "", "missing description")
+				}
+			})
+			forEachEvent(doc, func(category *DocCategory, group *DocGroup, event *DocEvent) {
+				if strings.TrimSpace(strings.Join(event.Description, "")) == "" {
+					report(group, eventKey(event), "", "missing description")
+				}
+			})
+		},
+	},
+	{
+		Name:        "param-description",
+		Description: "params should have a description",
+		Severity:    LintWarn,
+		check: func(doc *Document, report func(*DocGroup, string, string, string)) {
+			forEachRoute(doc, func(category *DocCategory, group *DocGroup, route *DocRoute) {
+				for _, name := range sortedParamNames(route.Params) {
+					if route.Params[name].Desc == "" {
+						report(group, routeKey(group, route), name, "missing param description")
+					}
+				}
+			})
+		},
+	},
+	{
+		Name:        "post-client-error",
+		Description: "http POST routes should document a 4xx response",
+		Severity:    LintWarn,
+		check: func(doc *Document, report func(*DocGroup, string, string, string)) {
+			forEachRoute(doc, func(category *DocCategory, group *DocGroup, route *DocRoute) {
+				if category.Name != categoryHTTP || route.Method != http.MethodPost {
+					return
+				}
+				for statusCode := range route.ResponseBodies {
+					if statusCode >= 400 && statusCode < 500 {
+						return
+					}
+				}
+				report(group, routeKey(group, route), "", "no 4xx response documented")
+			})
+		},
+	},
+	{
+		Name:        "duplicate-route",
+		Description: "method and path pairs should be unique",
+		Severity:    LintError,
+		check: func(doc *Document, report func(*DocGroup, string, string, string)) {
+			seen := make(map[string]*DocGroup)
+			forEachRoute(doc, func(category *DocCategory, group *DocGroup, route *DocRoute) {
+				key := routeKey(group, route)
+				if first, ok := seen[key]; ok {
+					report(group, key, "", "duplicate of a route in group "+first.Name)
+					return
+				}
+				seen[key] = group
+			})
+		},
+	},
+}
+
+// Lint checks doc against the LintRules, with the severities overridden by config.
+func Lint(doc *Document, config LintConfig) ([]*LintFinding, error) {
+	for name, severity := range config {
+		if findLintRule(name) == nil {
+			return nil, errors.Errorf("unknown lint rule: %v", name)
+		}
+		if severity != LintOff && severity != LintWarn && severity != LintError {
+			return nil, errors.Errorf("invalid severity for lint rule %v: %v", name, severity)
+		}
+	}
+
+	findings := make([]*LintFinding, 0)
+	for _, rule := range LintRules {
+		severity := rule.Severity
+		if s, ok := config[rule.Name]; ok {
+			severity = s
+		}
+		if severity == LintOff {
+			continue
+		}
+
+		rule.check(doc, func(group *DocGroup, route string, field string, message string) {
+			findings = append(findings, &LintFinding{
+				Rule:     rule.Name,
+				Severity: severity,
+				Group:    group.Name,
+				Route:    route,
+				Field:    field,
+				Message:  message,
+			})
+		})
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity == LintError && findings[j].Severity != LintError
+	})
+
+	return findings, nil
+}
+
+func (f *LintFinding) String() string {
+	field := ""
+	if f.Field != "" {
+		field = " `" + f.Field + "`"
+	}
+
+	return fmt.Sprintf("%-5s %v > [%v]%v: %v (%v)", f.Severity, f.Group, f.Route, field, f.Message, f.Rule)
+}
+
+func findLintRule(name string) *LintRule {
+	for _, rule := range LintRules {
+		if rule.Name == name {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+func forEachRoute(doc *Document, fn func(category *DocCategory, group *DocGroup, route *DocRoute)) {
+	for _, category := range doc.Categories {
+		for _, group := range category.Groups {
+			for _, route := range group.Routes {
+				fn(category, group, route)
+			}
+		}
+	}
+}
+
+func forEachEvent(doc *Document, fn func(category *DocCategory, group *DocGroup, event *DocEvent)) {
+	for _, category := range doc.Categories {
+		for _, group := range category.Groups {
+			for _, event := range group.Events {
+				fn(category, group, event)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	doc := &Document{Categories: []*DocCategory{
+		{Name: categoryHTTP, Groups: []*DocGroup{
+			{
+				Name: "Users",
+				Routes: []*DocRoute{
+					{
+						Method:         http.MethodPost,
+						Path:           "/users",
+						Description:    []string{"Creates a user."},
+						ResponseBodies: map[int]interface{}{http.StatusCreated: nil},
+					},
+					{
+						Method:         http.MethodGet,
+						Path:           "/users/{id}",
+						Params:         map[string]*DocValue{"id": {Value: "1", APIMDType: "string"}},
+						ResponseBodies: map[int]interface{}{http.StatusOK: nil},
+					},
+				},
+			},
+			{
+				Name: "Admin",
+				Routes: []*DocRoute{
+					{
+						Method:         http.MethodPost,
+						Path:           "/users",
+						Description:    []string{"Creates a user."},
+						ResponseBodies: map[int]interface{}{http.StatusBadRequest: nil},
+					},
+				},
+			},
+		}},
+		{Name: categoryConsumedMessages, Groups: []*DocGroup{
+			{
+				Name: "Messages",
+				Events: []*DocEvent{
+					{Direction: EventConsumed, EventName: "/user/deleted/v1", Description: []string{"Deletes."}},
+					{Direction: EventConsumed, EventName: "/user/created/v1"},
+				},
+			},
+		}},
+	}}
+
+	findings, err := Lint(doc, LintConfig{"param-description": LintError})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	got := make([]string, 0, len(findings))
+	for _, f := range findings {
+		got = append(got, f.String())
+	}
+	want := []string{
+		"error Users > [GET /users/{id}] `id`: missing param description (param-description)",
+		"error Admin > [POST /users]: duplicate of a route in group Users (duplicate-route)",
+		"warn  Users > [GET /users/{id}]: missing description (route-description)",
+		"warn  Messages > [CONSUMED /user/created/v1]: missing description (route-description)",
+		"warn  Users > [POST /users]: no 4xx response documented (post-client-error)",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("want:\n%v\ngot:\n%v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("want: %s\ngot:  %s", want[i], got[i])
+		}
+	}
+
+	if _, err := Lint(doc, LintConfig{"unknown-rule": LintWarn}); err == nil {
+		t.Errorf("want error for unknown rule")
+	}
+}
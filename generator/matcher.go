@@ -0,0 +1,77 @@
+package generator
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// routeMatcher finds the documented http route of a request by its method and path.
+type routeMatcher struct {
+	routes []*matcherRoute
+}
+
+type matcherRoute struct {
+	group  *DocGroup
+	route  *DocRoute
+	regex  *regexp.Regexp
+	params []string
+}
+
+// routeMatch is a matched route, with the values of its path params.
+type routeMatch struct {
+	Group  *DocGroup
+	Route  *DocRoute
+	Params map[string]string
+}
+
+func newRouteMatcher(doc *Document) *routeMatcher {
+	m := &routeMatcher{routes: make([]*matcherRoute, 0)}
+	for _, group := range httpGroups(doc) {
+		for _, route := range group.Routes {
+			r := &matcherRoute{group: group, route: route, params: make([]string, 0)}
+			path := routePath(group, route, func(name string) string {
+				r.params = append(r.params, name)
+				return "\x00"
+			})
+			parts := strings.Split(path, "\x00")
+			for i, part := range parts {
+				parts[i] = regexp.QuoteMeta(part)
+			}
+			r.regex = regexp.MustCompile("^" + strings.Join(parts, "([^/]+)") + "$")
+			m.routes = append(m.routes, r)
+		}
+	}
+
+	// routes with less params are more specific, eg. /users/me is matched before /users/{id}
+	sort.SliceStable(m.routes, func(i, j int) bool {
+		return len(m.routes[i].params) < len(m.routes[j].params)
+	})
+
+	return m
+}
+
+// match returns the route of method and path. Without a route of method, it returns nil, and the methods of the
+// routes matching path.
+func (m *routeMatcher) match(method string, path string) (*routeMatch, []string) {
+	allowed := make([]string, 0)
+	for _, r := range m.routes {
+		values := r.regex.FindStringSubmatch(path)
+		if values == nil {
+			continue
+		}
+		if !strings.EqualFold(r.route.Method, method) {
+			allowed = append(allowed, strings.ToUpper(r.route.Method))
+			continue
+		}
+
+		params := make(map[string]string, len(r.params))
+		for i, name := range r.params {
+			params[name] = values[i+1]
+		}
+		return &routeMatch{Group: r.group, Route: r.route, Params: params}, nil
+	}
+	sort.Strings(allowed)
+
+	return nil, allowed
+}
@@ -0,0 +1,220 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RequestValidationError is the problems of a request, the validation middleware responds with it as JSON.
+type RequestValidationError struct {
+	Method string `json:"method"`
+	// Path is the documented path of the route, empty for undocumented routes
+	Path     string   `json:"path,omitempty"`
+	Failures []string `json:"failures"`
+}
+
+func (e *RequestValidationError) Error() string {
+	return "invalid request: " + strings.Join(e.Failures, "; ")
+}
+
+// DefaultMaxBodySize is the size limit of the validated request bodies, see: WithMaxBodySize
+const DefaultMaxBodySize = 1 << 20
+
+// ValidationOption configures the validation middleware.
+type ValidationOption func(v *requestValidator)
+
+// WithReportOnly passes the invalid requests to the next handler too, after calling report with their problems,
+// eg. to log them while rolling out the validation.
+func WithReportOnly(report func(r *http.Request, err *RequestValidationError)) ValidationOption {
+	return func(v *requestValidator) {
+		v.report = report
+	}
+}
+
+// WithMaxBodySize sets the size limit of the validated request bodies in bytes, larger bodies are invalid.
+// It is DefaultMaxBodySize by default.
+func WithMaxBodySize(size int64) ValidationOption {
+	return func(v *requestValidator) {
+		v.maxBodySize = size
+	}
+}
+
+type requestValidator struct {
+	doc         *Document
+	matcher     *routeMatcher
+	report      func(r *http.Request, err *RequestValidationError)
+	maxBodySize int64
+	next        http.Handler
+}
+
+// NewValidationMiddleware returns a middleware, which validates the requests against the http routes of doc:
+// the route of the method and path, the types and enums of the path and query params, the required query params,
+// and the request body, see: ValidateBody
+// Invalid requests get a 400 response with a RequestValidationError, unless WithReportOnly is used.
+func NewValidationMiddleware(doc *Document, opts ...ValidationOption) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		v := &requestValidator{doc: doc, matcher: newRouteMatcher(doc), maxBodySize: DefaultMaxBodySize, next: next}
+		for _, opt := range opts {
+			opt(v)
+		}
+
+		return v
+	}
+}
+
+func (v *requestValidator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	validationErr := v.validate(r)
+	if validationErr == nil {
+		v.next.ServeHTTP(w, r)
+		return
+	}
+
+	if v.report != nil {
+		v.report(r, validationErr)
+		v.next.ServeHTTP(w, r)
+		return
+	}
+
+	b, err := json.Marshal(validationErr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write(b)
+}
+
+// validate returns the problems of r, or nil for valid requests. The body of r is read up to the size limit, and
+// replaced with a reader starting with the read part.
+func (v *requestValidator) validate(r *http.Request) *RequestValidationError {
+	validationErr := &RequestValidationError{Method: r.Method, Failures: make([]string, 0)}
+
+	match, allowed := v.matcher.match(r.Method, r.URL.Path)
+	if match == nil {
+		if len(allowed) > 0 {
+			validationErr.Failures = append(validationErr.Failures, fmt.Sprintf(
+				"method %v is not documented for %v, want one of: %v", r.Method, r.URL.Path, strings.Join(allowed, ", ")))
+		} else {
+			validationErr.Failures = append(validationErr.Failures, "route "+r.URL.Path+" is not documented")
+		}
+		return validationErr
+	}
+	route := match.Route
+	validationErr.Path = match.Group.Prefix + route.Path
+
+	names := make([]string, 0, len(match.Params))
+	for name := range match.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if param, ok := route.Params[name]; ok {
+			problems := validateParam("path param "+name, param, match.Params[name])
+			validationErr.Failures = append(validationErr.Failures, problems...)
+		}
+	}
+
+	query := r.URL.Query()
+	names = routeQueryNames(route)
+	sort.Strings(names)
+	for _, name := range names {
+		param, ok := route.Params[name]
+		if !ok {
+			param, ok = route.Query[name]
+		}
+		if !ok {
+			continue
+		}
+		values, ok := query[name]
+		if !ok {
+			if !param.Opt {
+				validationErr.Failures = append(validationErr.Failures, "query param "+name+": missing required param")
+			}
+			continue
+		}
+		if param.APIMDType == "array" {
+			continue
+		}
+		for _, value := range values {
+			validationErr.Failures = append(validationErr.Failures, validateParam("query param "+name, param, value)...)
+		}
+	}
+
+	validationErr.Failures = append(validationErr.Failures, v.validateBody(r, route)...)
+
+	if len(validationErr.Failures) == 0 {
+		return nil
+	}
+
+	return validationErr
+}
+
+func (v *requestValidator) validateBody(r *http.Request, route *DocRoute) []string {
+	if route.RequestBody == nil {
+		return []string{}
+	}
+	if r.Body == nil {
+		return []string{"body: missing request body"}
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, v.maxBodySize+1))
+	r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+	if err != nil {
+		return []string{"body: " + err.Error()}
+	}
+	if int64(len(body)) > v.maxBodySize {
+		return []string{fmt.Sprintf("body: request body is larger than %v bytes", v.maxBodySize)}
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return []string{"body: missing request body"}
+	}
+
+	problems, err := ValidateBody(v.doc, route.RequestBody, body)
+	if err != nil {
+		return []string{"body: " + err.Error()}
+	}
+	for i, problem := range problems {
+		problems[i] = "body " + problem
+	}
+
+	return problems
+}
+
+// readCloser is a request body read partially, which closes the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// validateParam returns the problems of the value of a path or query param.
+func validateParam(name string, param *DocValue, value string) []string {
+	switch param.APIMDType {
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return []string{fmt.Sprintf("%v: want number, got %q", name, value)}
+		}
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return []string{fmt.Sprintf("%v: want boolean, got %q", name, value)}
+		}
+	}
+
+	if len(param.Enum) > 0 {
+		for _, member := range param.Enum {
+			if member == value {
+				return []string{}
+			}
+		}
+		return []string{fmt.Sprintf("%v: %q is not one of the enum values", name, value)}
+	}
+
+	return []string{}
+}
@@ -0,0 +1,132 @@
+package generator
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidationMiddleware(t *testing.T) {
+	doc := &Document{
+		Categories: []*DocCategory{{Name: categoryHTTP, Groups: []*DocGroup{{
+			Name:   "Users",
+			Prefix: "/api",
+			Routes: []*DocRoute{{
+				Name:   "Update",
+				Method: http.MethodPut,
+				Path:   "/users/{id}{?tags,limit}",
+				Params: map[string]*DocValue{
+					"id":    {Value: "1", APIMDType: "number"},
+					"tags":  {Value: "a,b", APIMDType: "array", Opt: true},
+					"limit": {Value: "10", APIMDType: "string", Enum: []string{"10", "20"}},
+				},
+				RequestBody: map[string]interface{}{
+					"user":  &DocRef{Name: "User"},
+					"admin": &DocValue{Value: "true", APIMDType: "boolean"},
+				},
+			}},
+		}}}},
+		DataStructures: []*DocDataStructure{{Name: "User", Value: map[string]interface{}{
+			"age":        &DocValue{Value: "30", APIMDType: "number"},
+			"deleted_at": &DocValue{Null: true, Nullable: true, APIMDType: "string"},
+		}}},
+	}
+
+	var gotBody string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := NewValidationMiddleware(doc)(next)
+
+	validBody := `{"admin":true,"user":{"age":30,"deleted_at":null}}`
+	for _, data := range []struct {
+		Method string
+		Target string
+		Body   string
+		Status int
+		Want   string
+	}{
+		{
+			Method: http.MethodPut,
+			Target: "/api/users/1?limit=10",
+			Body:   validBody,
+			Status: http.StatusNoContent,
+		},
+		{
+			Method: http.MethodPut,
+			Target: "/api/users/me?limit=30",
+			Body:   `{"admin":"yes"}`,
+			Status: http.StatusBadRequest,
+			Want: `{"method":"PUT","path":"/api/users/{id}{?tags,limit}","failures":[` +
+				`"path param id: want number, got \"me\"",` +
+				`"query param limit: \"30\" is not one of the enum values",` +
+				`"body $.admin: want boolean, got string",` +
+				`"body $.user: missing required field"]}`,
+		},
+		{
+			Method: http.MethodPut,
+			Target: "/api/users/1",
+			Status: http.StatusBadRequest,
+			Want: `{"method":"PUT","path":"/api/users/{id}{?tags,limit}","failures":[` +
+				`"query param limit: missing required param","body: missing request body"]}`,
+		},
+		{
+			Method: http.MethodGet,
+			Target: "/api/users/1",
+			Status: http.StatusBadRequest,
+			Want:   `{"method":"GET","failures":["method GET is not documented for /api/users/1, want one of: PUT"]}`,
+		},
+		{
+			Method: http.MethodGet,
+			Target: "/api/posts",
+			Status: http.StatusBadRequest,
+			Want:   `{"method":"GET","failures":["route /api/posts is not documented"]}`,
+		},
+	} {
+		gotBody = ""
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(data.Method, data.Target, strings.NewReader(data.Body)))
+
+		if recorder.Code != data.Status || recorder.Body.String() != data.Want {
+			t.Errorf("%v %v: got %v %v, want %v %v", data.Method, data.Target, recorder.Code, recorder.Body.String(),
+				data.Status, data.Want)
+		}
+		if data.Status == http.StatusNoContent && gotBody != data.Body {
+			t.Errorf("the next handler should get the body, got %q", gotBody)
+		}
+	}
+
+	var reported *RequestValidationError
+	handler = NewValidationMiddleware(doc, WithReportOnly(func(r *http.Request, err *RequestValidationError) {
+		reported = err
+	}))(next)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/api/users/1", strings.NewReader(validBody)))
+	if recorder.Code != http.StatusNoContent || gotBody != validBody {
+		t.Errorf("report only should pass the request, got %v %q", recorder.Code, gotBody)
+	}
+	if reported == nil || reported.Error() != "invalid request: query param limit: missing required param" {
+		t.Errorf("unexpected report: %v", reported)
+	}
+
+	handler = NewValidationMiddleware(doc, WithMaxBodySize(10))(next)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/api/users/1?limit=10", strings.NewReader(validBody)))
+	want := `{"method":"PUT","path":"/api/users/{id}{?tags,limit}","failures":["body: request body is larger than 10 bytes"]}`
+	if recorder.Code != http.StatusBadRequest || recorder.Body.String() != want {
+		t.Errorf("got %v %v, want 400 %v", recorder.Code, recorder.Body.String(), want)
+	}
+
+	gotBody = ""
+	handler = NewValidationMiddleware(doc, WithMaxBodySize(10), WithReportOnly(func(r *http.Request, err *RequestValidationError) {
+		reported = err
+	}))(next)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/api/users/1?limit=10", strings.NewReader(validBody)))
+	if gotBody != validBody {
+		t.Errorf("report only should pass the whole body over the limit, got %q", gotBody)
+	}
+}
@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// The header and query params selecting the response of NewMockHandler.
+const (
+	MockStatusHeader  = "X-Mock-Status"
+	MockStatusQuery   = "_status"
+	MockExampleHeader = "X-Mock-Example"
+	MockExampleQuery  = "_example"
+)
+
+// mockHandler responds to the documented http routes with their examples.
+type mockHandler struct {
+	doc     *Document
+	matcher *routeMatcher
+}
+
+// NewMockHandler returns a mock of the http routes of doc. It responds to the requests of the documented routes,
+// matched by their method and path, with the example body of a documented status code:
+//
+//	X-Mock-Status: 404, or ?_status=404   the status code, the first documented 2xx status code by default
+//	X-Mock-Example: 1, or ?_example=1     the One Of variant of the body, the first one by default
+//
+// Undocumented routes get 404, routes with other methods 405, and undocumented status codes 400. The responses
+// allow any origin, so the mock can be used from a browser.
+func NewMockHandler(doc *Document) http.Handler {
+	return &mockHandler{doc: doc, matcher: newRouteMatcher(doc)}
+}
+
+func (h *mockHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+		w.Header().Set("Access-Control-Allow-Methods", r.Header.Get("Access-Control-Request-Method"))
+		w.Header().Set("Access-Control-Allow-Headers", r.Header.Get("Access-Control-Request-Headers"))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	match, allowed := h.matcher.match(r.Method, r.URL.Path)
+	if match == nil && len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if match == nil {
+		http.NotFound(w, r)
+		return
+	}
+	route := match.Route
+
+	statusCode := mockDefaultStatus(route)
+	if status := mockParam(r, MockStatusHeader, MockStatusQuery); status != "" {
+		var err error
+		statusCode, err = strconv.Atoi(status)
+		if _, ok := route.ResponseBodies[statusCode]; err != nil || !ok {
+			http.Error(w, "status "+status+" is not documented", http.StatusBadRequest)
+			return
+		}
+	}
+
+	body := route.ResponseBodies[statusCode]
+	if body == nil {
+		w.WriteHeader(statusCode)
+		return
+	}
+
+	example := responseExample(h.doc, route, statusCode)
+	if index := mockParam(r, MockExampleHeader, MockExampleQuery); index != "" {
+		oneOf, ok := body.(*DocOneOf)
+		i, err := strconv.Atoi(index)
+		if !ok || err != nil || i < 0 || i >= len(oneOf.Variants) {
+			http.Error(w, "example "+index+" is not documented", http.StatusBadRequest)
+			return
+		}
+		example = exampleValue(h.doc, oneOf.Variants[i])
+	}
+
+	b, err := json.Marshal(example)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(b)
+}
+
+// mockDefaultStatus returns the first documented 2xx status code, or the first documented one.
+func mockDefaultStatus(route *DocRoute) int {
+	statusCodes := sortedStatusCodes(route.ResponseBodies)
+	for _, statusCode := range statusCodes {
+		if statusCode >= 200 && statusCode < 300 {
+			return statusCode
+		}
+	}
+	if len(statusCodes) > 0 {
+		return statusCodes[0]
+	}
+
+	return http.StatusOK
+}
+
+func mockParam(r *http.Request, header string, query string) string {
+	if value := r.Header.Get(header); value != "" {
+		return value
+	}
+
+	return r.URL.Query().Get(query)
+}
@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMockHandler(t *testing.T) {
+	doc := &Document{
+		Categories: []*DocCategory{{Name: categoryHTTP, Groups: []*DocGroup{{
+			Name:   "Users",
+			Prefix: "/api",
+			Routes: []*DocRoute{
+				{
+					Name:   "Update",
+					Method: http.MethodPut,
+					Path:   "/users/{id}{?tags,limit}",
+					Params: map[string]*DocValue{
+						"id":    {Value: "1", APIMDType: "string"},
+						"tags":  {Value: "a,b", APIMDType: "array"},
+						"limit": {Value: "10", APIMDType: "number", Opt: true},
+					},
+					ResponseBodies: map[int]interface{}{
+						http.StatusOK:       &DocArray{Item: &DocRef{Name: "User"}},
+						http.StatusNotFound: nil,
+					},
+				},
+				{
+					Name:           "Me",
+					Method:         http.MethodPut,
+					Path:           "/users/me",
+					ResponseBodies: map[int]interface{}{http.StatusCreated: &DocValue{Value: "me", APIMDType: "string"}},
+				},
+			},
+		}}}},
+		DataStructures: []*DocDataStructure{{Name: "User", Value: map[string]interface{}{
+			"age":        &DocValue{Value: "30", APIMDType: "number"},
+			"deleted_at": &DocValue{Null: true, Nullable: true, APIMDType: "string"},
+		}}},
+	}
+	handler := NewMockHandler(doc)
+
+	for _, data := range []struct {
+		Method string
+		Target string
+		Header http.Header
+		Status int
+		Body   string
+	}{
+		{
+			Method: http.MethodPut,
+			Target: "/api/users/1",
+			Status: http.StatusOK,
+			Body:   `[{"age":30,"deleted_at":null}]`,
+		},
+		{
+			Method: http.MethodPut,
+			Target: "/api/users/me",
+			Status: http.StatusCreated,
+			Body:   `"me"`,
+		},
+		{
+			Method: http.MethodPut,
+			Target: "/api/users/1",
+			Header: http.Header{MockStatusHeader: {"404"}},
+			Status: http.StatusNotFound,
+		},
+		{
+			Method: http.MethodPut,
+			Target: "/api/users/1?_status=500",
+			Status: http.StatusBadRequest,
+			Body:   "status 500 is not documented\n",
+		},
+		{
+			Method: http.MethodPut,
+			Target: "/api/users/1?_example=1",
+			Status: http.StatusBadRequest,
+			Body:   "example 1 is not documented\n",
+		},
+		{
+			Method: http.MethodGet,
+			Target: "/api/users/1",
+			Status: http.StatusMethodNotAllowed,
+			Body:   "Method Not Allowed\n",
+		},
+		{
+			Method: http.MethodGet,
+			Target: "/api/posts",
+			Status: http.StatusNotFound,
+			Body:   "404 page not found\n",
+		},
+		{
+			Method: http.MethodOptions,
+			Target: "/api/users/1",
+			Header: http.Header{"Access-Control-Request-Method": {http.MethodPut}},
+			Status: http.StatusNoContent,
+		},
+	} {
+		req := httptest.NewRequest(data.Method, data.Target, nil)
+		for key, values := range data.Header {
+			req.Header[key] = values
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		if recorder.Code != data.Status || recorder.Body.String() != data.Body {
+			t.Errorf("%v %v: got %v %q, want %v %q", data.Method, data.Target, recorder.Code, recorder.Body.String(),
+				data.Status, data.Body)
+		}
+		if data.Status == http.StatusMethodNotAllowed && recorder.Header().Get("Allow") != http.MethodPut {
+			t.Errorf("got Allow %q", recorder.Header().Get("Allow"))
+		}
+	}
+}
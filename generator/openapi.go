@@ -0,0 +1,149 @@
+package generator
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const openAPIVersion = "3.1.0"
+
+// openAPISchemas is the prefix of the references to the data structures in the components of an OpenAPI document.
+const openAPISchemas = "#/components/schemas/"
+
+// MarshalOpenAPI converts the http routes of doc to an OpenAPI 3.1 document: the groups become tags, the path and
+// query params parameters, and the bodies JSON Schemas with their examples, see: JSONSchemas
+// The events are not part of it. The definitions have no version, so info.version is 0.0.0.
+// Routes which differ only in the part of their path after #, are documented by the first of them.
+func MarshalOpenAPI(doc *Document) ([]byte, error) {
+	refs := make(map[string]bool)
+	paths := make(map[string]interface{})
+	tags := make([]interface{}, 0)
+	used := make(map[string]bool)
+	for _, group := range httpGroups(doc) {
+		tags = append(tags, map[string]interface{}{"name": group.Name})
+		for _, route := range group.Routes {
+			path := routePath(group, route, func(name string) string { return "{" + name + "}" })
+			item, ok := paths[path].(map[string]interface{})
+			if !ok {
+				item = make(map[string]interface{})
+				paths[path] = item
+			}
+			method := strings.ToLower(route.Method)
+			if _, ok := item[method]; ok {
+				continue
+			}
+
+			operationID := slug(group.Name + " " + route.Name)
+			for i := 2; used[operationID]; i++ {
+				operationID = slug(group.Name+" "+route.Name) + "-" + strconv.Itoa(i)
+			}
+			used[operationID] = true
+
+			item[method] = openAPIOperation(doc, group, route, operationID, refs)
+		}
+	}
+
+	result := map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info":    map[string]interface{}{"title": doc.Name, "version": "0.0.0"},
+		"tags":    tags,
+		"paths":   paths,
+	}
+	if schemas := schemaDefinitions(doc, openAPISchemas, refs); len(schemas) > 0 {
+		result["components"] = map[string]interface{}{"schemas": schemas}
+	}
+
+	b, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return b, nil
+}
+
+func openAPIOperation(doc *Document, group *DocGroup, route *DocRoute, operationID string, refs map[string]bool) map[string]interface{} {
+	result := map[string]interface{}{
+		"operationId": operationID,
+		"summary":     route.Name,
+		"tags":        []string{group.Name},
+	}
+	if len(route.Description) > 0 {
+		result["description"] = strings.Join(route.Description, "\n")
+	}
+
+	parameters := make([]interface{}, 0)
+	for _, name := range docPathParamNames(group, route) {
+		parameters = append(parameters, openAPIParameter(doc, name, "path", examplePathParam(route, name), refs))
+	}
+	queryNames := routeQueryNames(route)
+	sort.Strings(queryNames)
+	for _, name := range queryNames {
+		value, ok := route.Params[name]
+		if !ok {
+			value, ok = route.Query[name]
+		}
+		if ok {
+			parameters = append(parameters, openAPIParameter(doc, name, "query", value, refs))
+		}
+	}
+	if len(parameters) > 0 {
+		result["parameters"] = parameters
+	}
+
+	if route.RequestBody != nil {
+		result["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  openAPIContent(doc, route.RequestBody, route.RequestExample, refs),
+		}
+	}
+
+	responses := make(map[string]interface{}, len(route.ResponseBodies))
+	for _, statusCode := range sortedStatusCodes(route.ResponseBodies) {
+		description := http.StatusText(statusCode)
+		if description == "" {
+			description = "Response " + strconv.Itoa(statusCode)
+		}
+		response := map[string]interface{}{"description": description}
+		if body := route.ResponseBodies[statusCode]; body != nil {
+			response["content"] = openAPIContent(doc, body, route.ResponseExamples[statusCode], refs)
+		}
+		responses[strconv.Itoa(statusCode)] = response
+	}
+	if len(responses) == 0 {
+		responses["default"] = map[string]interface{}{"description": "undocumented response"}
+	}
+	result["responses"] = responses
+
+	return result
+}
+
+func openAPIParameter(doc *Document, name string, in string, value *DocValue, refs map[string]bool) map[string]interface{} {
+	schema := jsonSchema(doc, value, openAPISchemas, refs)
+	delete(schema, "description")
+
+	result := map[string]interface{}{
+		"name":     name,
+		"in":       in,
+		"required": in == "path" || !value.Opt,
+		"schema":   schema,
+	}
+	if value.Desc != "" {
+		result["description"] = value.Desc
+	}
+
+	return result
+}
+
+func openAPIContent(doc *Document, tree interface{}, example interface{}, refs map[string]bool) map[string]interface{} {
+	mediaType := map[string]interface{}{"schema": jsonSchema(doc, tree, openAPISchemas, refs)}
+	if example != nil {
+		mediaType["example"] = example
+	}
+
+	return map[string]interface{}{"application/json": mediaType}
+}
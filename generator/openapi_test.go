@@ -0,0 +1,173 @@
+package generator
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestMarshalOpenAPI(t *testing.T) {
+	doc := &Document{
+		Name: "Users",
+		Categories: []*DocCategory{
+			{Name: categoryHTTP, Groups: []*DocGroup{{
+				Name:   "Users",
+				Prefix: "/api",
+				Routes: []*DocRoute{
+					{
+						Name:        "Update",
+						Method:      http.MethodPut,
+						Path:        "/users/{id}{?tags,limit}",
+						Description: []string{"Updates a user."},
+						Params: map[string]*DocValue{
+							"id":    {Value: "1", APIMDType: "string", Desc: "user id"},
+							"tags":  {Value: "a,b", APIMDType: "array"},
+							"limit": {Value: "10", APIMDType: "number", Opt: true},
+						},
+						RequestBody:    map[string]interface{}{"user": &DocRef{Name: "User"}},
+						RequestExample: map[string]interface{}{"user": map[string]interface{}{"name": "John"}},
+						ResponseBodies: map[int]interface{}{
+							http.StatusOK:       &DocArray{Item: &DocRef{Name: "User"}},
+							http.StatusNotFound: nil,
+						},
+					},
+					{Name: "Delete", Method: http.MethodDelete, Path: "/users/{id}", Params: map[string]*DocValue{"id": {Value: "1", APIMDType: "string"}}},
+					{Name: "Delete", Method: http.MethodDelete, Path: "/users/{id}#admin", Params: map[string]*DocValue{"id": {Value: "2", APIMDType: "string"}}},
+				},
+			}}},
+			{Name: categoryFiredEvents, Groups: []*DocGroup{{
+				Name:   "Events",
+				Events: []*DocEvent{{Name: "Created", Direction: EventFired, EventName: "/user/created"}},
+			}}},
+		},
+		DataStructures: []*DocDataStructure{
+			{Name: "User", Value: map[string]interface{}{
+				"name":    &DocValue{Value: "John", APIMDType: "string"},
+				"address": &DocRef{Name: "Address"},
+			}},
+			{Name: "Address", Value: map[string]interface{}{"city": &DocValue{Value: "Budapest", APIMDType: "string"}}},
+			{Name: "Unused", Value: map[string]interface{}{}},
+		},
+	}
+
+	b, err := MarshalOpenAPI(doc)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := validateOpenAPI(b); err != nil {
+		t.Fatalf("invalid OpenAPI document: %v\n%s", err, b)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	paths := got["paths"].(map[string]interface{})
+	if len(paths) != 1 || len(paths["/api/users/{id}"].(map[string]interface{})) != 2 {
+		t.Errorf("want the put and the first delete operation of /api/users/{id}, got: %s", mustJSON(paths))
+	}
+
+	put := paths["/api/users/{id}"].(map[string]interface{})["put"]
+	wantPut := `{"description":"Updates a user.","operationId":"users-update","parameters":[` +
+		`{"description":"user id","in":"path","name":"id","required":true,"schema":{"examples":["1"],"type":"string"}},` +
+		`{"in":"query","name":"limit","required":false,"schema":{"examples":[10],"type":"number"}},` +
+		`{"in":"query","name":"tags","required":true,"schema":{"items":{"type":"string"},"type":"array"}}],` +
+		`"requestBody":{"content":{"application/json":{"example":{"user":{"name":"John"}},` +
+		`"schema":{"properties":{"user":{"$ref":"#/components/schemas/User"}},"required":["user"],"type":"object"}}},"required":true},` +
+		`"responses":{"200":{"content":{"application/json":{"schema":{"items":{"$ref":"#/components/schemas/User"},"type":"array"}}},"description":"OK"},` +
+		`"404":{"description":"Not Found"}},` +
+		`"summary":"Update","tags":["Users"]}`
+	if got := mustJSON(put); got != wantPut {
+		t.Errorf("put operation:\ngot:  %v\nwant: %v", got, wantPut)
+	}
+
+	schemas := got["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	if _, ok := schemas["Address"]; !ok || len(schemas) != 2 {
+		t.Errorf("want the referred data structures in the components, got: %s", mustJSON(schemas))
+	}
+}
+
+// openAPIRefRegex matches the references of an OpenAPI document.
+var openAPIRefRegex = regexp.MustCompile(`"\$ref":\s*"([^"]*)"`)
+
+// validateOpenAPI checks the required fields of an OpenAPI 3.1 document, the path params of the operations and the
+// references to the components.
+func validateOpenAPI(b []byte) error {
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    *struct {
+			Title   *string `json:"title"`
+			Version string  `json:"version"`
+		} `json:"info"`
+		Paths map[string]map[string]*struct {
+			Parameters []*struct {
+				Name     string      `json:"name"`
+				In       string      `json:"in"`
+				Required bool        `json:"required"`
+				Schema   interface{} `json:"schema"`
+			} `json:"parameters"`
+			Responses map[string]*struct {
+				Description *string `json:"description"`
+			} `json:"responses"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	err := json.Unmarshal(b, &doc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if doc.OpenAPI != openAPIVersion {
+		return errors.Errorf("openapi: %q", doc.OpenAPI)
+	}
+	if doc.Info == nil || doc.Info.Title == nil || doc.Info.Version == "" {
+		return errors.New("missing info title or version")
+	}
+	for path, item := range doc.Paths {
+		if !strings.HasPrefix(path, "/") {
+			return errors.Errorf("path %v does not start with /", path)
+		}
+		placeholders := docPathParamRegex.FindAllStringSubmatch(path, -1)
+		for method, operation := range item {
+			if len(operation.Responses) == 0 {
+				return errors.Errorf("%v %v: missing responses", method, path)
+			}
+			for code, response := range operation.Responses {
+				if response.Description == nil {
+					return errors.Errorf("%v %v: missing description of response %v", method, path, code)
+				}
+			}
+			pathParams := make(map[string]bool)
+			for _, parameter := range operation.Parameters {
+				if parameter.Schema == nil || (parameter.In != "path" && parameter.In != "query") {
+					return errors.Errorf("%v %v: invalid parameter %v", method, path, parameter.Name)
+				}
+				if parameter.In == "path" {
+					if !parameter.Required {
+						return errors.Errorf("%v %v: path parameter %v is not required", method, path, parameter.Name)
+					}
+					pathParams[parameter.Name] = true
+				}
+			}
+			for _, placeholder := range placeholders {
+				if !pathParams[placeholder[1]] {
+					return errors.Errorf("%v %v: missing path parameter %v", method, path, placeholder[1])
+				}
+			}
+		}
+	}
+	for _, match := range openAPIRefRegex.FindAllStringSubmatch(string(b), -1) {
+		if _, ok := doc.Components.Schemas[strings.TrimPrefix(match[1], openAPISchemas)]; !ok ||
+			!strings.HasPrefix(match[1], openAPISchemas) {
+			return errors.Errorf("unresolved reference %v", match[1])
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,735 @@
+package generator
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	groupHeadingRegex     = regexp.MustCompile(`^### (.*) \[(.*)\]$`)
+	routeHeadingRegex     = regexp.MustCompile(`^#### (.*) \[(\S+) (.*)\]$`)
+	structureHeadingRegex = regexp.MustCompile(`^## (.*) \(object\)$`)
+	eventLabelRegex       = regexp.MustCompile("^(\\w+) (GEB|Centrifuge) event: `(.*)`$")
+	parenRegex            = regexp.MustCompile(`^\(([^)]*)\)(?: - (.*))?$`)
+	arrayTypeRegex        = regexp.MustCompile(`^array\[(.*)\]$`)
+	enumTypeRegex         = regexp.MustCompile(`^enum\[(.*)\]$`)
+	discriminatorRegex    = regexp.MustCompile("^Discriminator: `(.*)`$")
+)
+
+// outlineNode is a `+ ` list item of API Blueprint with its nested items, or an indented line of text.
+type outlineNode struct {
+	line     int
+	indent   int
+	item     bool
+	text     string
+	children []*outlineNode
+}
+
+type apimdParser struct {
+	lines []string
+	pos   int
+	doc   *Document
+}
+
+// ParseAPIMD parses API Blueprint, as it is rendered by RenderAPIMD, back into a Document.
+// Scalar bodies are rendered without their type, so their type is guessed from the value.
+func ParseAPIMD(data []byte) (*Document, error) {
+	p := &apimdParser{
+		lines: strings.Split(strings.Replace(string(data), "\r\n", "\n", -1), "\n"),
+		doc: &Document{
+			Categories: make([]*DocCategory, 0),
+		},
+	}
+
+	err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	if hasEventRoutes(p.doc) {
+		upgradeEventRoutes(p.doc)
+	}
+	// scalar bodies have no Body section
+	addExamples(p.doc)
+
+	return p.doc, nil
+}
+
+// hasEventRoutes tells whether the events of doc are documented as routes, like in the API.md of the generator
+// versions before DocEvent, see: upgradeEventRoutes
+func hasEventRoutes(doc *Document) bool {
+	for _, category := range doc.Categories {
+		if category.Name == categoryHTTP {
+			continue
+		}
+		for _, group := range category.Groups {
+			if len(group.Routes) > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (p *apimdParser) parse() error {
+	var category *DocCategory
+	var group *DocGroup
+	dataStructures := false
+
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		lineNum := p.pos + 1
+		p.pos++
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "FORMAT:"):
+
+		case line == "# Data Structures":
+			dataStructures = true
+
+		case strings.HasPrefix(line, "# "):
+			p.doc.Name = strings.TrimPrefix(line, "# ")
+
+		case strings.HasPrefix(line, "GENERATED, DO NOT EDIT"):
+			for p.pos < len(p.lines) && strings.HasPrefix(p.lines[p.pos], "- ") {
+				p.doc.Usage = append(p.doc.Usage, strings.TrimPrefix(p.lines[p.pos], "- "))
+				p.pos++
+			}
+
+		case dataStructures && structureHeadingRegex.MatchString(line):
+			name := structureHeadingRegex.FindStringSubmatch(line)[1]
+			value, err := p.parseObject(p.outline(0))
+			if err != nil {
+				return errors.Wrapf(err, "data structure %v", name)
+			}
+			p.doc.DataStructures = append(p.doc.DataStructures, &DocDataStructure{Name: name, Value: value})
+
+		case strings.HasPrefix(line, "## Group "):
+			category = &DocCategory{
+				Name:   strings.TrimPrefix(line, "## Group "),
+				Groups: make([]*DocGroup, 0),
+			}
+			p.doc.Categories = append(p.doc.Categories, category)
+
+		case groupHeadingRegex.MatchString(line):
+			if category == nil {
+				return errors.Errorf("line %v: group outside of a category", lineNum)
+			}
+			m := groupHeadingRegex.FindStringSubmatch(line)
+			group = &DocGroup{
+				Name:   m[1],
+				Prefix: m[2],
+				Routes: make([]*DocRoute, 0),
+			}
+			if group.Prefix == "/" {
+				group.Prefix = ""
+			}
+			category.Groups = append(category.Groups, group)
+
+		case strings.HasPrefix(line, "### "):
+			if category == nil {
+				return errors.Errorf("line %v: group outside of a category", lineNum)
+			}
+			group = &DocGroup{
+				Name:   strings.TrimPrefix(line, "### "),
+				Routes: make([]*DocRoute, 0),
+				Events: make([]*DocEvent, 0),
+			}
+			category.Groups = append(category.Groups, group)
+
+		case group != nil && group.Events != nil && strings.HasPrefix(line, "#### "):
+			event, err := p.parseEvent(strings.TrimPrefix(line, "#### "))
+			if err != nil {
+				return errors.Wrapf(err, "event %v", strings.TrimPrefix(line, "#### "))
+			}
+			group.Events = append(group.Events, event)
+
+		case routeHeadingRegex.MatchString(line):
+			if group == nil {
+				return errors.Errorf("line %v: route outside of a group", lineNum)
+			}
+			m := routeHeadingRegex.FindStringSubmatch(line)
+			route, err := p.parseRoute(m[1], m[2], strings.TrimPrefix(m[3], group.Prefix))
+			if err != nil {
+				return errors.Wrapf(err, "route [%v] %v", m[2], m[3])
+			}
+			group.Routes = append(group.Routes, route)
+
+		default:
+			return errors.Errorf("line %v: unexpected line: %v", lineNum, line)
+		}
+	}
+
+	return nil
+}
+
+func (p *apimdParser) parseRoute(name string, method string, path string) (*DocRoute, error) {
+	route := &DocRoute{
+		Name:           name,
+		Method:         method,
+		Path:           path,
+		Params:         make(map[string]*DocValue),
+		Query:          make(map[string]*DocValue),
+		ResponseBodies: make(map[int]interface{}),
+	}
+
+	for p.pos < len(p.lines) && p.lines[p.pos] != "" && !strings.HasPrefix(p.lines[p.pos], "+ ") && !strings.HasPrefix(p.lines[p.pos], "#") {
+		route.Description = append(route.Description, p.lines[p.pos])
+		p.pos++
+	}
+
+	for {
+		for p.pos < len(p.lines) && p.lines[p.pos] == "" {
+			p.pos++
+		}
+		if p.pos < len(p.lines) && strings.HasPrefix(p.lines[p.pos], "```") {
+			snippet, err := p.parseSnippet()
+			if err != nil {
+				return nil, err
+			}
+			route.Snippets = append(route.Snippets, snippet)
+			continue
+		}
+		if p.pos >= len(p.lines) || !strings.HasPrefix(p.lines[p.pos], "+ ") {
+			return route, nil
+		}
+
+		sections := p.outline(0)
+		for _, section := range sections {
+			var err error
+			switch {
+			case section.text == "Parameters":
+				err = p.parseParams(route, section.children)
+
+			case section.text == "Request":
+				route.RequestBody, err = p.parseBody(section)
+				if err == nil {
+					route.RequestExample, err = p.parseExample(section)
+				}
+
+			case strings.HasPrefix(section.text, "Response "):
+				statusCode, convErr := strconv.Atoi(strings.TrimPrefix(section.text, "Response "))
+				if convErr != nil {
+					return nil, errors.Errorf("line %v: invalid status code: %v", section.line, section.text)
+				}
+				route.ResponseBodies[statusCode], err = p.parseBody(section)
+				if err == nil {
+					var example interface{}
+					example, err = p.parseExample(section)
+					if example != nil {
+						if route.ResponseExamples == nil {
+							route.ResponseExamples = make(map[int]interface{})
+						}
+						route.ResponseExamples[statusCode] = example
+					}
+				}
+
+			default:
+				err = errors.Errorf("line %v: unexpected section: %v", section.line, section.text)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// parseEvent parses an event, its label tells the direction and the transport of the event.
+func (p *apimdParser) parseEvent(name string) (*DocEvent, error) {
+	event := &DocEvent{
+		Name:    name,
+		Headers: make(map[string]*DocValue),
+	}
+
+	for p.pos < len(p.lines) && p.lines[p.pos] == "" {
+		p.pos++
+	}
+	if p.pos >= len(p.lines) || !eventLabelRegex.MatchString(p.lines[p.pos]) {
+		return nil, errors.Errorf("line %v: missing event label", p.pos+1)
+	}
+	m := eventLabelRegex.FindStringSubmatch(p.lines[p.pos])
+	p.pos++
+	event.Direction = strings.ToLower(m[1])
+	event.EventName = m[3]
+	if m[2] == "Centrifuge" {
+		parts := strings.SplitN(m[3], ":", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("line %v: invalid Centrifuge channel: %v", p.pos, m[3])
+		}
+		event.Channel = &DocChannel{Namespace: parts[0], Name: parts[1]}
+	}
+
+	for p.pos < len(p.lines) && p.lines[p.pos] == "" {
+		p.pos++
+	}
+	for p.pos < len(p.lines) && p.lines[p.pos] != "" && !strings.HasPrefix(p.lines[p.pos], "+ ") && !strings.HasPrefix(p.lines[p.pos], "#") {
+		event.Description = append(event.Description, p.lines[p.pos])
+		p.pos++
+	}
+
+	for {
+		for p.pos < len(p.lines) && p.lines[p.pos] == "" {
+			p.pos++
+		}
+		if p.pos >= len(p.lines) || !strings.HasPrefix(p.lines[p.pos], "+ ") {
+			return event, nil
+		}
+
+		for _, section := range p.outline(0) {
+			var err error
+			switch section.text {
+			case "Headers":
+				for _, node := range section.children {
+					key, value, memberErr := p.parseMember(node)
+					if memberErr != nil {
+						return nil, memberErr
+					}
+					header, ok := value.(*DocValue)
+					if !ok {
+						return nil, errors.Errorf("line %v: invalid header: %v", node.line, node.text)
+					}
+					event.Headers[key] = header
+				}
+
+			case "Payload":
+				event.Payload, err = p.parseBody(section)
+				if err == nil {
+					event.PayloadExample, err = p.parseExample(section)
+				}
+
+			default:
+				err = errors.Errorf("line %v: unexpected section: %v", section.line, section.text)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// parseSnippet parses a fenced code block.
+func (p *apimdParser) parseSnippet() (*DocSnippet, error) {
+	start := p.pos + 1
+	snippet := &DocSnippet{Lang: strings.TrimPrefix(p.lines[p.pos], "```")}
+	code := make([]string, 0)
+	for p.pos++; p.pos < len(p.lines); p.pos++ {
+		if p.lines[p.pos] == "```" {
+			p.pos++
+			snippet.Code = strings.Join(code, "\n")
+			return snippet, nil
+		}
+		code = append(code, p.lines[p.pos])
+	}
+
+	return nil, errors.Errorf("line %v: unclosed code block", start)
+}
+
+func (p *apimdParser) parseParams(route *DocRoute, nodes []*outlineNode) error {
+	for _, node := range nodes {
+		key, value, err := p.parseMember(node)
+		if err != nil {
+			return err
+		}
+
+		v, ok := value.(*DocValue)
+		if !ok {
+			return errors.Errorf("line %v: invalid param: %v", node.line, node.text)
+		}
+		route.Params[key] = v
+	}
+
+	return nil
+}
+
+// parseBody parses the content of a Request, Response or Payload section.
+func (p *apimdParser) parseBody(section *outlineNode) (interface{}, error) {
+	if len(section.children) == 0 {
+		return nil, nil
+	}
+
+	node := section.children[0]
+	switch {
+	case node.text == "Attributes":
+		if len(node.children) == 1 && node.children[0].text == "One Of" {
+			return p.parseOneOf(node.children[0], "")
+		}
+		if len(node.children) == 2 && !node.children[0].item && node.children[1].text == "One Of" {
+			// the discriminator is the description of the attributes
+			m := discriminatorRegex.FindStringSubmatch(node.children[0].text)
+			if m == nil {
+				return nil, errors.Errorf("line %v: invalid discriminator: %v", node.children[0].line, node.children[0].text)
+			}
+			return p.parseOneOf(node.children[1], m[1])
+		}
+		return p.parseObject(node.children)
+
+	case strings.HasPrefix(node.text, "Attributes ("):
+		return p.parseParenType(node, strings.TrimPrefix(node.text, "Attributes "))
+
+	case !node.item:
+		// scalar bodies are indented text, instead of list items
+		return &DocValue{Value: node.text, APIMDType: guessAPIMDType(node.text)}, nil
+
+	default:
+		return nil, errors.Errorf("line %v: unexpected body: %v", node.line, node.text)
+	}
+}
+
+// parseExample parses the Body of a Request or Response section, it returns nil without a Body.
+func (p *apimdParser) parseExample(section *outlineNode) (interface{}, error) {
+	for _, node := range section.children {
+		if !node.item || node.text != "Body" || len(node.children) == 0 {
+			continue
+		}
+
+		lines := make([]string, 0)
+		var appendLines func(nodes []*outlineNode)
+		appendLines = func(nodes []*outlineNode) {
+			for _, n := range nodes {
+				lines = append(lines, strings.Repeat(" ", n.indent-node.children[0].indent)+n.text)
+				appendLines(n.children)
+			}
+		}
+		appendLines(node.children)
+
+		var example interface{}
+		decoder := json.NewDecoder(strings.NewReader(strings.Join(lines, "\n")))
+		decoder.UseNumber()
+		err := decoder.Decode(&example)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %v: invalid body", node.line)
+		}
+
+		return example, nil
+	}
+
+	return nil, nil
+}
+
+func (p *apimdParser) parseOneOf(node *outlineNode, discriminator string) (interface{}, error) {
+	result := &DocOneOf{
+		Discriminator: discriminator,
+		Variants:      make([]interface{}, 0, len(node.children)),
+	}
+	for _, child := range node.children {
+		var variant interface{}
+		var err error
+		switch {
+		case child.text == "Properties" && len(child.children) == 1 && strings.HasPrefix(child.children[0].text, "Include "):
+			variant = &DocRef{Name: strings.TrimPrefix(child.children[0].text, "Include ")}
+		case child.text == "Properties":
+			variant, err = p.parseObject(child.children)
+		default:
+			variant, err = p.parseItem(child)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Variants = append(result.Variants, variant)
+	}
+
+	return result, nil
+}
+
+func (p *apimdParser) parseObject(nodes []*outlineNode) (interface{}, error) {
+	result := make(map[string]interface{}, len(nodes))
+	for _, node := range nodes {
+		key, value, err := p.parseMember(node)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+// parseMember parses a named item of an object, eg. "`key`: `value` (string) - desc".
+func (p *apimdParser) parseMember(node *outlineNode) (string, interface{}, error) {
+	if !strings.HasPrefix(node.text, "`") {
+		return "", nil, errors.Errorf("line %v: invalid member: %v", node.line, node.text)
+	}
+	end := strings.Index(node.text[1:], "`")
+	if end < 0 {
+		return "", nil, errors.Errorf("line %v: invalid member: %v", node.line, node.text)
+	}
+	key := node.text[1 : end+1]
+	rest := node.text[end+2:]
+
+	switch {
+	case rest == "":
+		value, err := p.parseObject(node.children)
+		return key, value, err
+
+	case strings.HasPrefix(rest, ": `"):
+		value, err := p.parseValue(node, rest[2:])
+		return key, value, err
+
+	case strings.HasPrefix(rest, " ("):
+		value, err := p.parseParenType(node, rest[1:])
+		return key, value, err
+
+	case rest == "(array)":
+		value, err := p.parseLegacyArray(node)
+		return key, value, err
+
+	default:
+		return "", nil, errors.Errorf("line %v: invalid member: %v", node.line, node.text)
+	}
+}
+
+// parseLegacyArray parses an array rendered by older generator versions, eg. "`key`(array)", with all of its
+// elements as items, and nested objects and arrays as "(object)" items. The array is summarized with its first item.
+func (p *apimdParser) parseLegacyArray(node *outlineNode) (interface{}, error) {
+	result := &DocArray{}
+	if len(node.children) == 0 {
+		return result, nil
+	}
+
+	child := node.children[0]
+	if child.text != "(object)" {
+		item, err := p.parseItem(child)
+		if err != nil {
+			return nil, err
+		}
+		result.Item = item
+		return result, nil
+	}
+
+	if len(child.children) > 0 && !isLegacyMember(child.children[0]) {
+		item, err := p.parseLegacyArray(child)
+		if err != nil {
+			return nil, err
+		}
+		result.Item = item
+		return result, nil
+	}
+
+	item, err := p.parseObject(child.children)
+	if err != nil {
+		return nil, err
+	}
+	result.Item = item
+
+	return result, nil
+}
+
+// isLegacyMember tells if node is a named member of an object rendered by older generator versions, rather than an
+// element of an array.
+func isLegacyMember(node *outlineNode) bool {
+	if node.text == "(object)" || !strings.HasPrefix(node.text, "`") {
+		return false
+	}
+	end := strings.Index(node.text[1:], "`")
+	if end < 0 {
+		return false
+	}
+	rest := node.text[end+2:]
+
+	return rest == "" || rest == "(array)" || strings.HasPrefix(rest, ": `")
+}
+
+// parseItem parses an unnamed item of an array or a One Of section, eg. "`value` (string)" or "(object)".
+func (p *apimdParser) parseItem(node *outlineNode) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(node.text, "`"):
+		return p.parseValue(node, node.text)
+
+	case strings.HasPrefix(node.text, "null ("):
+		value, err := p.parseMeta(node, strings.TrimPrefix(node.text, "null "))
+		if err != nil {
+			return nil, err
+		}
+		value.Null = true
+		return value, nil
+
+	case node.text == "(object)":
+		return p.parseObject(node.children)
+
+	case strings.HasPrefix(node.text, "("):
+		return p.parseParenType(node, node.text)
+
+	default:
+		return nil, errors.Errorf("line %v: invalid item: %v", node.line, node.text)
+	}
+}
+
+// parseParenType parses the part of an item after its name, when it has no example value:
+// an array type, a data structure reference, or the meta of a null value.
+func (p *apimdParser) parseParenType(node *outlineNode, text string) (interface{}, error) {
+	m := parenRegex.FindStringSubmatch(text)
+	if m == nil {
+		return nil, errors.Errorf("line %v: invalid type: %v", node.line, node.text)
+	}
+
+	if am := arrayTypeRegex.FindStringSubmatch(m[1]); am != nil {
+		return p.parseArray(node, am[1], m[2])
+	}
+
+	if strings.Contains(m[1], ",") || isAPIMDType(m[1]) || enumTypeRegex.MatchString(m[1]) {
+		value, err := p.parseMeta(node, text)
+		if err != nil {
+			return nil, err
+		}
+		value.Null = true
+		return value, nil
+	}
+
+	return &DocRef{Name: m[1]}, nil
+}
+
+func (p *apimdParser) parseArray(node *outlineNode, itemType string, constraints string) (interface{}, error) {
+	result := &DocArray{}
+
+	for _, constraint := range strings.Split(constraints, ", ") {
+		var err error
+		switch {
+		case constraint == "":
+		case constraint == "unique items":
+			result.UniqueItems = true
+		case strings.HasPrefix(constraint, "min items: "):
+			result.MinItems, err = strconv.Atoi(strings.TrimPrefix(constraint, "min items: "))
+		case strings.HasPrefix(constraint, "max items: "):
+			result.MaxItems, err = strconv.Atoi(strings.TrimPrefix(constraint, "max items: "))
+		default:
+			err = errors.Errorf("unknown constraint: %v", constraint)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %v", node.line)
+		}
+	}
+
+	if len(node.children) == 0 {
+		if isAPIMDType(itemType) || itemType == "object" || itemType == "array" {
+			return nil, errors.Errorf("line %v: missing array item: %v", node.line, node.text)
+		}
+		result.Item = &DocRef{Name: itemType}
+		return result, nil
+	}
+
+	item, err := p.parseItem(node.children[0])
+	if err != nil {
+		return nil, err
+	}
+	result.Item = item
+
+	return result, nil
+}
+
+// parseValue parses "`value` (meta) - desc".
+func (p *apimdParser) parseValue(node *outlineNode, text string) (*DocValue, error) {
+	end := strings.Index(text[1:], "`")
+	if end < 0 {
+		return nil, errors.Errorf("line %v: invalid value: %v", node.line, node.text)
+	}
+
+	value, err := p.parseMeta(node, strings.TrimPrefix(text[end+2:], " "))
+	if err != nil {
+		return nil, err
+	}
+	value.Value = text[1 : end+1]
+
+	return value, nil
+}
+
+// parseMeta parses "(type, optional, nullable) - desc", and the Members of enum[type] values.
+func (p *apimdParser) parseMeta(node *outlineNode, text string) (*DocValue, error) {
+	m := parenRegex.FindStringSubmatch(text)
+	if m == nil {
+		return nil, errors.Errorf("line %v: invalid value meta: %v", node.line, node.text)
+	}
+
+	parts := strings.Split(m[1], ", ")
+	result := &DocValue{
+		APIMDType: parts[0],
+		Desc:      m[2],
+	}
+	if em := enumTypeRegex.FindStringSubmatch(parts[0]); em != nil {
+		result.APIMDType = em[1]
+		result.Enum = make([]string, 0)
+		for _, child := range node.children {
+			if child.text != "Members" {
+				continue
+			}
+			for _, member := range child.children {
+				result.Enum = append(result.Enum, strings.Trim(member.text, "`"))
+			}
+		}
+	}
+	for _, part := range parts[1:] {
+		switch part {
+		case "optional":
+			result.Opt = true
+		case "nullable":
+			result.Nullable = true
+		default:
+			return nil, errors.Errorf("line %v: unknown value attribute: %v", node.line, part)
+		}
+	}
+
+	return result, nil
+}
+
+// outline reads the list items starting at the current line, which are indented at least by indent.
+func (p *apimdParser) outline(indent int) []*outlineNode {
+	root := &outlineNode{indent: indent - 1}
+	stack := []*outlineNode{root}
+
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line == "" {
+			if p.pos+1 < len(p.lines) && strings.HasPrefix(p.lines[p.pos+1], strings.Repeat(" ", indent+4)) {
+				// blank line before an indented scalar body
+				p.pos++
+				continue
+			}
+			break
+		}
+
+		trimmed := strings.TrimLeft(line, " ")
+		lineIndent := len(line) - len(trimmed)
+		if lineIndent < indent || (lineIndent == indent && !strings.HasPrefix(trimmed, "+ ")) {
+			break
+		}
+
+		node := &outlineNode{
+			line:   p.pos + 1,
+			indent: lineIndent,
+			item:   strings.HasPrefix(trimmed, "+ "),
+			text:   strings.TrimPrefix(trimmed, "+ "),
+		}
+		for stack[len(stack)-1].indent >= lineIndent {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+		parent.children = append(parent.children, node)
+		stack = append(stack, node)
+		p.pos++
+	}
+
+	return root.children
+}
+
+func isAPIMDType(t string) bool {
+	switch t {
+	case "string", "number", "boolean", "array":
+		return true
+	default:
+		return false
+	}
+}
+
+func guessAPIMDType(value string) string {
+	if value == "true" || value == "false" {
+		return "boolean"
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return "number"
+	}
+
+	return "string"
+}
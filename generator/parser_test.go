@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseAPIMD(t *testing.T) {
+	doc := testRoundTripDocument(t)
+
+	b, err := RenderAPIMD(doc)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	got, err := ParseAPIMD(b)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if !reflect.DeepEqual(doc, got) {
+		b2, _ := RenderAPIMD(got)
+		t.Errorf("round trip mismatch, want:\n%s\ngot:\n%s", b, b2)
+	}
+}
+
+// TestParseLegacyAPIMD parses the output of the generator before the parser was added, arrays were rendered with
+// all of their elements, and nested objects and arrays as "(object)" items.
+func TestParseLegacyAPIMD(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/baseline_API.md")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	doc, err := ParseAPIMD(b)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	route := doc.Categories[0].Groups[0].Routes[0]
+	wantUser := map[string]interface{}{
+		"age":   &DocValue{Value: "30", APIMDType: "number"},
+		"id":    &DocValue{Value: "u1", APIMDType: "string", Desc: "user id"},
+		"grid":  &DocArray{Item: &DocArray{Item: &DocValue{Value: "1", APIMDType: "number"}}},
+		"nicks": &DocArray{Item: &DocValue{Value: "jd", APIMDType: "string"}},
+		"tags": &DocArray{Item: map[string]interface{}{
+			"name": &DocValue{Value: "admin", APIMDType: "string"},
+		}},
+	}
+	if !reflect.DeepEqual(route.RequestBody, map[string]interface{}{"user": wantUser}) {
+		t.Errorf("request body: %s", mustJSON(route.RequestBody))
+	}
+	if !reflect.DeepEqual(route.ResponseBodies[http.StatusOK], wantUser) {
+		t.Errorf("response body: %s", mustJSON(route.ResponseBodies[http.StatusOK]))
+	}
+	if param := route.Params["limit"]; param == nil || !param.Opt || param.APIMDType != "number" {
+		t.Errorf("limit param: %+v", param)
+	}
+
+	// the events were documented as routes
+	events := make([]string, 0)
+	forEachEvent(doc, func(_ *DocCategory, group *DocGroup, event *DocEvent) {
+		if len(group.Routes) != 0 || group.Prefix != "" {
+			t.Errorf("%v: events should replace the routes, got prefix %q and %v routes", group.Name, group.Prefix, len(group.Routes))
+		}
+		events = append(events, event.Direction+" "+event.EventName+" "+mustJSON(event.PayloadExample))
+	})
+	wantEvents := []string{
+		`consumed /msg/users/user/deleted/v1 {"id":"u1","tags":[{"name":"admin"}]}`,
+		`fired /event/users/user/created/v1 {"id":"u2","tags":[{"name":"new"}]}`,
+		`fired users:online {"id":"u3"}`,
+	}
+	if !reflect.DeepEqual(events, wantEvents) {
+		t.Errorf("got events:\n%v\nwant:\n%v", events, wantEvents)
+	}
+	created := doc.Categories[2].Groups[0].Events[0]
+	if !reflect.DeepEqual(created.Description, []string{"Fired after a user is created."}) {
+		t.Errorf("description: %v", created.Description)
+	}
+	online := doc.Categories[3].Groups[0].Events[0]
+	if !reflect.DeepEqual(online.Channel, &DocChannel{Namespace: "users", Name: "online"}) {
+		t.Errorf("channel: %+v", online.Channel)
+	}
+
+	// the upgraded document is rendered in the current format without changes
+	rendered, err := RenderAPIMD(doc)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	parsed, err := ParseAPIMD(rendered)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if report := Diff(doc, parsed); len(report.Changes) != 0 {
+		t.Errorf("rendered upgraded document differs:\n%v", report.Summary())
+	}
+}
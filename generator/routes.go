@@ -0,0 +1,180 @@
+package generator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// routerPlaceholderRegex matches the placeholders of the router paths after normalizePath, also with patterns, eg.
+// {id:[0-9]+} or {path...}
+var routerPlaceholderRegex = regexp.MustCompile(`{[^}]*}`)
+
+// RouterRoute is a route registered on a router, an empty Method matches every method.
+type RouterRoute struct {
+	Method string
+	Path   string
+}
+
+// RouteLister lists the routes registered on a router, see: ServeMux, RouteListerFunc
+type RouteLister interface {
+	Routes() []RouterRoute
+}
+
+// RouteListerFunc lists the routes of routers which can enumerate them, eg. echo.Echo.Routes()
+type RouteListerFunc func() []RouterRoute
+
+func (f RouteListerFunc) Routes() []RouterRoute {
+	return f()
+}
+
+// ServeMux is an http.ServeMux, which records the patterns registered on it.
+// The patterns are listed the way the http.ServeMux in effect routes them: with the go 1.22 patterns, eg.
+// "GET /users/{id}" is listed with its method and its wildcard, others with an empty method. The http.ServeMux of
+// older go versions, or of main modules before go 1.22, treats the whole pattern as a literal host and path.
+type ServeMux struct {
+	*http.ServeMux
+
+	mu       sync.Mutex
+	patterns []string
+}
+
+func NewServeMux() *ServeMux {
+	return &ServeMux{ServeMux: http.NewServeMux(), patterns: make([]string, 0)}
+}
+
+func (m *ServeMux) Handle(pattern string, handler http.Handler) {
+	m.ServeMux.Handle(pattern, handler)
+	m.record(pattern)
+}
+
+func (m *ServeMux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.ServeMux.HandleFunc(pattern, handler)
+	m.record(pattern)
+}
+
+func (m *ServeMux) record(pattern string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.patterns = append(m.patterns, pattern)
+}
+
+func (m *ServeMux) Routes() []RouterRoute {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	enhanced := enhancedMuxPatterns()
+	routes := make([]RouterRoute, 0, len(m.patterns))
+	for _, pattern := range m.patterns {
+		route := RouterRoute{Path: pattern}
+		if i := strings.IndexAny(pattern, " \t"); i >= 0 && enhanced {
+			route.Method = pattern[:i]
+			route.Path = strings.TrimSpace(pattern[i:])
+		}
+		// patterns may start with a host
+		if i := strings.Index(route.Path, "/"); i > 0 {
+			route.Path = route.Path[i:]
+		}
+		route.Path = strings.Replace(route.Path, ":", "\\:", -1)
+		if enhanced {
+			route.Path = strings.TrimSuffix(route.Path, "{$}")
+		} else {
+			route.Path = literalBraces.Replace(route.Path)
+		}
+		routes = append(routes, route)
+	}
+
+	return routes
+}
+
+// literalBraces escapes the braces of literal paths, so they are not placeholders, see: routerPathKey
+var literalBraces = strings.NewReplacer("{", "%7B", "}", "%7D")
+
+var (
+	enhancedMuxPatternsOnce sync.Once
+	enhancedMuxPatternsOK   bool
+)
+
+// enhancedMuxPatterns tells whether http.ServeMux routes by the methods and wildcards of the patterns, which depends
+// on the go version, the go version of the main module, and the httpmuxgo121 GODEBUG setting.
+func enhancedMuxPatterns() bool {
+	enhancedMuxPatternsOnce.Do(func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /{name}", func(w http.ResponseWriter, r *http.Request) {})
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/probe", nil))
+		enhancedMuxPatternsOK = recorder.Code == http.StatusOK
+	})
+
+	return enhancedMuxPatternsOK
+}
+
+// RouteReport is the difference of the documented http routes and the routes of a router.
+type RouteReport struct {
+	// Undocumented are the routes of the router, which are not documented
+	Undocumented []RouterRoute
+	// Missing are the documented routes, which are not registered on the router
+	Missing []RouterRoute
+}
+
+// CompareRoutes compares the http routes of doc with the routes of lister by their methods and paths. The
+// :param placeholders of the router paths are mapped to {param}, and the names of the placeholders are ignored.
+// See: apimdtest.TestRoutes
+func CompareRoutes(doc *Document, lister RouteLister) *RouteReport {
+	documented := make([]RouterRoute, 0)
+	for _, group := range httpGroups(doc) {
+		for _, route := range group.Routes {
+			documented = append(documented, RouterRoute{
+				Method: strings.ToUpper(route.Method),
+				Path:   routePath(group, route, func(name string) string { return "{" + name + "}" }),
+			})
+		}
+	}
+	routes := lister.Routes()
+
+	report := &RouteReport{Undocumented: make([]RouterRoute, 0), Missing: make([]RouterRoute, 0)}
+	for _, route := range routes {
+		if !containsRoute(documented, route) {
+			report.Undocumented = append(report.Undocumented, route)
+		}
+	}
+	for _, route := range documented {
+		if !containsRoute(routes, route) {
+			report.Missing = append(report.Missing, route)
+		}
+	}
+	sortRouterRoutes(report.Undocumented)
+	sortRouterRoutes(report.Missing)
+
+	return report
+}
+
+func containsRoute(routes []RouterRoute, route RouterRoute) bool {
+	path := routerPathKey(route.Path)
+	for _, r := range routes {
+		if (r.Method == "" || route.Method == "" || strings.EqualFold(r.Method, route.Method)) &&
+			routerPathKey(r.Path) == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+// routerPathKey returns path with unnamed placeholders, eg. /users/{} for /users/:id
+func routerPathKey(path string) string {
+	return routerPlaceholderRegex.ReplaceAllString(normalizePath(path), "{}")
+}
+
+func sortRouterRoutes(routes []RouterRoute) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+}
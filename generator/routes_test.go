@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCompareRoutes(t *testing.T) {
+	doc := &Document{Categories: []*DocCategory{{Name: categoryHTTP, Groups: []*DocGroup{{
+		Name:   "Users",
+		Prefix: "/api",
+		Routes: []*DocRoute{
+			{Name: "Update", Method: http.MethodPut, Path: "/users/{id}{?tags,limit}"},
+			{Name: "List", Method: http.MethodGet, Path: "/users"},
+			{Name: "Delete", Method: http.MethodDelete, Path: "/users/{id}#admin"},
+		},
+	}}}}}
+
+	report := CompareRoutes(doc, RouteListerFunc(func() []RouterRoute {
+		return []RouterRoute{
+			{Method: http.MethodPut, Path: "/api/users/:user_id"},
+			{Method: http.MethodDelete, Path: "/api/users/:id"},
+			{Method: http.MethodPost, Path: "/api/users"},
+		}
+	}))
+	want := &RouteReport{
+		Undocumented: []RouterRoute{{Method: http.MethodPost, Path: "/api/users"}},
+		Missing:      []RouterRoute{{Method: http.MethodGet, Path: "/api/users"}},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("got %+v, want %+v", report, want)
+	}
+}
+
+func TestServeMux(t *testing.T) {
+	doc := &Document{Categories: []*DocCategory{{Name: categoryHTTP, Groups: []*DocGroup{{
+		Name:   "Users",
+		Prefix: "/api",
+		Routes: []*DocRoute{
+			{Name: "Update", Method: http.MethodPut, Path: "/users/{id}"},
+			{Name: "Status", Method: http.MethodGet, Path: "/users/{id}/status"},
+		},
+	}}}}}
+
+	mux := NewServeMux()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	mux.HandleFunc("PUT /api/users/{id}", handler)
+	mux.HandleFunc("example.com/api/users/{$}", handler)
+	mux.Handle("/api/users/:id/status", http.HandlerFunc(handler))
+	mux.HandleFunc("/health", handler)
+
+	// the routes are listed the way the mux routes the requests
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/api/users/1", nil))
+	routed := recorder.Code == http.StatusOK
+	if routed != enhancedMuxPatterns() {
+		t.Fatalf("PUT /api/users/1 routed: %v, enhanced patterns: %v", routed, enhancedMuxPatterns())
+	}
+
+	wantRoutes := []RouterRoute{
+		{Path: "/api/users/%7Bid%7D"},
+		{Path: "/api/users/%7B$%7D"},
+		{Path: "/api/users/\\:id/status"},
+		{Path: "/health"},
+	}
+	want := &RouteReport{
+		Undocumented: []RouterRoute{{Path: "/api/users/%7B$%7D"}, {Path: "/api/users/%7Bid%7D"}, {Path: "/api/users/\\:id/status"}, {Path: "/health"}},
+		Missing:      []RouterRoute{{Method: http.MethodPut, Path: "/api/users/{id}"}, {Method: http.MethodGet, Path: "/api/users/{id}/status"}},
+	}
+	if routed {
+		wantRoutes = []RouterRoute{
+			{Method: http.MethodPut, Path: "/api/users/{id}"},
+			{Path: "/api/users/"},
+			{Path: "/api/users/\\:id/status"},
+			{Path: "/health"},
+		}
+		want = &RouteReport{
+			Undocumented: []RouterRoute{{Path: "/api/users/"}, {Path: "/api/users/\\:id/status"}, {Path: "/health"}},
+			Missing:      []RouterRoute{{Method: http.MethodGet, Path: "/api/users/{id}/status"}},
+		}
+	}
+	if routes := mux.Routes(); !reflect.DeepEqual(routes, wantRoutes) {
+		t.Errorf("got %+v, want %+v", routes, wantRoutes)
+	}
+	if report := CompareRoutes(doc, mux); !reflect.DeepEqual(report, want) {
+		t.Errorf("got %+v, want %+v", report, want)
+	}
+}
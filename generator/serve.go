@@ -0,0 +1,33 @@
+package generator
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+)
+
+const previewVersionPath = "/_apimd/version"
+
+// newPreviewHandler serves doc like NewHandler, with a live reloading HTML page. The page reloads itself when the
+// version of the document changes, eg. when the serve command is restarted by cmd/apimd with changed definitions.
+func newPreviewHandler(doc *Document) (http.Handler, error) {
+	export, err := MarshalDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha1.Sum(export)
+	version := hex.EncodeToString(sum[:])
+
+	page := singleHTMLPage(doc)
+	page.LiveReload = true
+	page.Version = version
+	page.VersionPath = previewVersionPath
+
+	h, err := newDocsHandler(doc, page)
+	if err != nil {
+		return nil, err
+	}
+	h.files[previewVersionPath] = newDocsFile("text/plain; charset=utf-8", []byte(version))
+
+	return h, nil
+}
@@ -0,0 +1,57 @@
+package generator
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPreviewHandler(t *testing.T) {
+	doc := testRoundTripDocument(t)
+
+	get := func(h http.Handler, path string) string {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%v: status %v", path, rec.Code)
+		}
+		b, _ := ioutil.ReadAll(rec.Body)
+		return string(b)
+	}
+
+	handler, err := newPreviewHandler(doc)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	version := get(handler, previewVersionPath)
+	if version == "" {
+		t.Fatalf("missing version")
+	}
+	page := get(handler, "/")
+	if !strings.Contains(page, `var version = "`+version+`";`) || !strings.Contains(page, `fetch("\/_apimd\/version")`) {
+		t.Errorf("page should poll the version, got:\n%s", page)
+	}
+
+	same, err := newPreviewHandler(testRoundTripDocument(t))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if get(same, previewVersionPath) != version {
+		t.Errorf("want the same version for the same document")
+	}
+
+	doc.Categories[0].Groups[0].Routes[0].Name = "Replace"
+	changed, err := newPreviewHandler(doc)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if get(changed, previewVersionPath) == version {
+		t.Errorf("want a new version for a changed document")
+	}
+
+	if page, err := RenderHTML(doc); err != nil || strings.Contains(string(page), "_apimd") {
+		t.Errorf("the pages of RenderHTML should not reload, got: %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package generator
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	siteIndexFile          = "index.html"
+	siteDataStructuresFile = "data-structures.html"
+	siteSearchIndexFile    = "search-index.js"
+)
+
+// htmlSearchEntry is a route or an event in the search index of the HTML site, events have their direction as
+// Method, and their name as Path.
+type htmlSearchEntry struct {
+	Name   string `json:"name"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	URL    string `json:"url"`
+	Text   string `json:"text"`
+}
+
+// WriteHTMLSite writes doc as a static HTML site to dir: an index.html, a page for every group, a page for the
+// data structures and the search index of the search boxes. The pages need no network access, they can be opened
+// from the file system as well.
+func WriteHTMLSite(doc *Document, dir string) error {
+	pages := siteHTMLPages(doc)
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for file, page := range pages {
+		b, err := renderHTML(page)
+		if err != nil {
+			return errors.Wrapf(err, "rendering %v", file)
+		}
+
+		err = ioutil.WriteFile(filepath.Join(dir, file), b, 0644)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	b, err := siteSearchIndex(doc, pages[siteIndexFile].groupFiles)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, siteSearchIndexFile), b, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// siteHTMLPages returns the pages of the site by file name.
+func siteHTMLPages(doc *Document) map[string]*htmlPage {
+	groupFiles := make(map[*DocGroup]string)
+	used := map[string]bool{siteIndexFile: true, siteDataStructuresFile: true}
+	for _, category := range doc.Categories {
+		for _, group := range category.Groups {
+			name := slug(category.Name + " " + group.Name)
+			file := name + ".html"
+			for i := 2; used[file]; i++ {
+				file = name + "-" + strconv.Itoa(i) + ".html"
+			}
+			used[file] = true
+			groupFiles[group] = file
+		}
+	}
+
+	newPage := func(title string) *htmlPage {
+		return &htmlPage{
+			Doc:                doc,
+			Title:              title,
+			SearchIndex:        siteSearchIndexFile,
+			indexFile:          siteIndexFile,
+			groupFiles:         groupFiles,
+			dataStructuresFile: siteDataStructuresFile,
+		}
+	}
+
+	pages := make(map[string]*htmlPage)
+
+	index := newPage(doc.Name)
+	index.Index = true
+	pages[siteIndexFile] = index
+
+	for _, category := range doc.Categories {
+		for _, group := range category.Groups {
+			page := newPage(group.Name + " - " + doc.Name)
+			page.Categories = []*DocCategory{{Name: category.Name, Groups: []*DocGroup{group}}}
+			pages[groupFiles[group]] = page
+		}
+	}
+
+	if len(doc.DataStructures) > 0 {
+		page := newPage("Data Structures - " + doc.Name)
+		page.DataStructures = doc.DataStructures
+		pages[siteDataStructuresFile] = page
+	}
+
+	return pages
+}
+
+// siteSearchIndex returns the script which sets the search index of the site.
+// It is a script instead of JSON, because browsers don't allow fetching files for pages opened from the file system.
+func siteSearchIndex(doc *Document, groupFiles map[*DocGroup]string) ([]byte, error) {
+	entries := make([]*htmlSearchEntry, 0)
+	forEachRoute(doc, func(category *DocCategory, group *DocGroup, route *DocRoute) {
+		fields := make(map[string]bool)
+		for name := range route.Params {
+			fields[name] = true
+		}
+		for name := range route.Query {
+			fields[name] = true
+		}
+		refs := make(map[string]bool)
+		collectFieldNames(doc, route.RequestBody, fields, refs)
+		for _, body := range route.ResponseBodies {
+			collectFieldNames(doc, body, fields, refs)
+		}
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		entries = append(entries, &htmlSearchEntry{
+			Name:   route.Name,
+			Method: route.Method,
+			Path:   group.Prefix + route.Path,
+			URL:    groupFiles[group] + "#" + routeAnchor(group, route),
+			Text:   searchText(group, route) + " " + strings.Join(names, " "),
+		})
+	})
+
+	forEachEvent(doc, func(category *DocCategory, group *DocGroup, event *DocEvent) {
+		fields := make(map[string]bool)
+		for name := range event.Headers {
+			fields[name] = true
+		}
+		collectFieldNames(doc, event.Payload, fields, make(map[string]bool))
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		entries = append(entries, &htmlSearchEntry{
+			Name:   event.Name,
+			Method: strings.ToUpper(event.Direction),
+			Path:   event.EventName,
+			URL:    groupFiles[group] + "#" + eventAnchor(event),
+			Text:   eventSearchText(group, event) + " " + strings.Join(names, " "),
+		})
+	})
+
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return []byte("var apimdSearchIndex = " + string(b) + ";\n"), nil
+}
+
+// collectFieldNames adds the field names of tree to names, including the fields of the referenced data structures.
+// refs holds the data structures which were already visited.
+func collectFieldNames(doc *Document, tree interface{}, names map[string]bool, refs map[string]bool) {
+	switch t := tree.(type) {
+	case map[string]interface{}:
+		for k, v := range t {
+			names[k] = true
+			collectFieldNames(doc, v, names, refs)
+		}
+	case *DocArray:
+		collectFieldNames(doc, t.Item, names, refs)
+	case *DocOneOf:
+		for _, v := range t.Variants {
+			collectFieldNames(doc, v, names, refs)
+		}
+	case *DocRef:
+		if refs[t.Name] {
+			return
+		}
+		refs[t.Name] = true
+		for _, ds := range doc.DataStructures {
+			if ds.Name == t.Name {
+				collectFieldNames(doc, ds.Value, names, refs)
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	SnippetCurl   = "curl"
+	SnippetHTTPie = "httpie"
+)
+
+// addSnippets adds a ready to run request example of every http route for each of the tools.
+func addSnippets(doc *Document, baseURL string, tools []string) error {
+	for _, group := range httpGroups(doc) {
+		for _, route := range group.Routes {
+			for _, tool := range tools {
+				code, err := snippet(doc, group, route, baseURL, tool)
+				if err != nil {
+					return errors.Wrapf(err, "snippet of route: [%v] %v", route.Method, route.Path)
+				}
+				route.Snippets = append(route.Snippets, &DocSnippet{Lang: "sh", Code: code})
+			}
+		}
+	}
+
+	return nil
+}
+
+func snippet(doc *Document, group *DocGroup, route *DocRoute, baseURL string, tool string) (string, error) {
+	requestURL := exampleURL(baseURL, group, route)
+
+	body := ""
+	if route.RequestBody != nil {
+		b, err := json.Marshal(requestExample(doc, route))
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		body = string(b)
+	}
+
+	switch tool {
+	case SnippetCurl:
+		lines := []string{"curl " + shellQuote(requestURL)}
+		if route.Method != http.MethodGet || body != "" {
+			lines[0] = "curl -X " + route.Method + " " + shellQuote(requestURL)
+		}
+		if body != "" {
+			lines = append(lines, "  -H "+shellQuote("Content-Type: application/json"), "  -d "+shellQuote(body))
+		}
+		return strings.Join(lines, " \\\n"), nil
+
+	case SnippetHTTPie:
+		command := "http " + route.Method + " " + shellQuote(requestURL)
+		if body != "" {
+			// HTTPie sends the standard input as a JSON body
+			command = "echo " + shellQuote(body) + " | " + command
+		}
+		return command, nil
+
+	default:
+		return "", errors.Errorf("unknown snippet tool: %v", tool)
+	}
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAddSnippets(t *testing.T) {
+	doc := &Document{Categories: []*DocCategory{{Name: categoryHTTP, Groups: []*DocGroup{{
+		Name:   "Users",
+		Prefix: "/api",
+		Routes: []*DocRoute{{
+			Name:   "Update",
+			Method: http.MethodPut,
+			Path:   "/users/{id}{?tags,limit}",
+			Params: map[string]*DocValue{
+				"id":    {Value: "a b", APIMDType: "string"},
+				"tags":  {Value: "a,b", APIMDType: "array"},
+				"limit": {Value: "10", APIMDType: "number", Opt: true},
+			},
+			RequestBody: map[string]interface{}{
+				"admin": &DocValue{Value: "true", APIMDType: "boolean"},
+				"user":  &DocRef{Name: "User"},
+			},
+		}},
+	}}}}, DataStructures: []*DocDataStructure{{Name: "User", Value: map[string]interface{}{
+		"name": &DocValue{Value: "it's", APIMDType: "string"},
+	}}}}
+
+	err := addSnippets(doc, "http://localhost/", []string{SnippetCurl, SnippetHTTPie})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	snippets := doc.Categories[0].Groups[0].Routes[0].Snippets
+	body := `{"admin":true,"user":{"name":"it'\''s"}}`
+	want := []*DocSnippet{
+		{Lang: "sh", Code: "curl -X PUT 'http://localhost/api/users/a%20b?limit=10&tags=a&tags=b' \\\n" +
+			"  -H 'Content-Type: application/json' \\\n" +
+			"  -d '" + body + "'"},
+		{Lang: "sh", Code: "echo '" + body + "' | http PUT 'http://localhost/api/users/a%20b?limit=10&tags=a&tags=b'"},
+	}
+	if len(snippets) != len(want) {
+		t.Fatalf("got %v snippets, want %v", len(snippets), len(want))
+	}
+	for i := range want {
+		if *snippets[i] != *want[i] {
+			t.Errorf("got:\n%v\nwant:\n%v", snippets[i].Code, want[i].Code)
+		}
+	}
+
+	err = addSnippets(doc, "http://localhost", []string{"wget"})
+	if err == nil {
+		t.Errorf("want error for unknown tool")
+	}
+}
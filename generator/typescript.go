@@ -0,0 +1,390 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var tsIdentifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsReservedWords can not be the names of the fetch functions.
+var tsReservedWords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true, "debugger": true,
+	"default": true, "delete": true, "do": true, "else": true, "enum": true, "export": true, "extends": true,
+	"false": true, "finally": true, "for": true, "function": true, "if": true, "import": true, "in": true,
+	"instanceof": true, "new": true, "null": true, "return": true, "super": true, "switch": true, "this": true,
+	"throw": true, "true": true, "try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+	"yield": true, "let": true, "static": true, "implements": true, "interface": true, "package": true,
+	"private": true, "protected": true, "public": true, "await": true, "request": true,
+}
+
+const tsFetchRuntime = `export interface FetchOptions {
+  baseUrl: string;
+  init?: RequestInit;
+  fetch?: typeof fetch;
+}
+
+// ApiError is thrown for the non-2xx responses, with the decoded body.
+export class ApiError extends Error {
+  status: number;
+  body: unknown;
+
+  constructor(status: number, body: unknown) {
+    super("unexpected response: " + status);
+    this.status = status;
+    this.body = body;
+  }
+}
+
+async function request<T>(options: FetchOptions, method: string, path: string, query: object | undefined, body: unknown): Promise<T> {
+  let url = options.baseUrl.replace(/\/$/, "") + path;
+  if (query) {
+    const search = new URLSearchParams();
+    for (const [key, value] of Object.entries(query)) {
+      for (const v of Array.isArray(value) ? value : [value]) {
+        if (v !== undefined && v !== null) {
+          search.append(key, String(v));
+        }
+      }
+    }
+    if (search.toString()) {
+      url += "?" + search.toString();
+    }
+  }
+
+  const headers = new Headers(options.init && options.init.headers);
+  headers.set("Accept", "application/json");
+  const init: RequestInit = { ...options.init, method, headers };
+  if (body !== undefined) {
+    headers.set("Content-Type", "application/json");
+    init.body = JSON.stringify(body);
+  }
+
+  const response = await (options.fetch || fetch)(url, init);
+  const text = await response.text();
+  const data = text ? JSON.parse(text) : undefined;
+  if (!response.ok) {
+    throw new ApiError(response.status, data);
+  }
+
+  return data as T;
+}
+`
+
+// tsRoute holds the names of the declarations of an http route, which the fetch function of the route uses.
+type tsRoute struct {
+	group     *DocGroup
+	route     *DocRoute
+	name      string
+	params    string
+	query     string
+	queryOpt  bool
+	request   string
+	responses []string
+}
+
+// RenderTypeScript returns TypeScript declarations of the data structures, and of the params, the query, the
+// request and the response bodies of the routes of doc, named after the routes, eg. UpdateUserRequest and
+// UpdateUserResponse200. With fetch, a typed fetch function is added for every http route as well.
+func RenderTypeScript(doc *Document, fetch bool) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString("// GENERATED, DO NOT EDIT, to regenerate:\n")
+	for _, usage := range doc.Usage {
+		buf.WriteString("// - " + usage + "\n")
+	}
+
+	names := make(map[string]bool)
+	for _, ds := range doc.DataStructures {
+		names[exportedName(ds.Name)] = true
+		writeTSDeclaration(buf, "", exportedName(ds.Name), ds.Value)
+	}
+
+	uniqueName := func(group *DocGroup, name string) string {
+		result := exportedName(name)
+		if names[result] {
+			result = exportedName(group.Name + " " + name)
+		}
+		for i := 2; names[result]; i++ {
+			result = exportedName(group.Name+" "+name) + strconv.Itoa(i)
+		}
+		names[result] = true
+
+		return result
+	}
+
+	routes := make([]*tsRoute, 0)
+	for _, category := range doc.Categories {
+		for _, group := range category.Groups {
+			for _, route := range group.Routes {
+				routes = append(routes, writeTSRoute(buf, group, route, uniqueName(group, route.Name)))
+			}
+			for _, event := range group.Events {
+				writeTSEvent(buf, event, uniqueName(group, event.Name))
+			}
+		}
+	}
+
+	if fetch && len(routes) > 0 {
+		buf.WriteString("\n" + tsFetchRuntime)
+		for _, r := range routes {
+			writeTSFetch(buf, r)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func writeTSRoute(buf *bytes.Buffer, group *DocGroup, route *DocRoute, name string) *tsRoute {
+	r := &tsRoute{group: group, route: route, name: name, responses: make([]string, 0)}
+	comment := "// " + route.Name + " [" + route.Method + " " + group.Prefix + route.Path + "]"
+	declare := func(name string, tree interface{}) {
+		writeTSDeclaration(buf, comment, name, tree)
+		comment = ""
+	}
+
+	params := make(map[string]interface{})
+	for _, param := range docPathParamNames(group, route) {
+		params[param] = examplePathParam(route, param)
+	}
+	if len(params) > 0 {
+		r.params = name + "Params"
+		declare(r.params, params)
+	}
+
+	query := make(map[string]interface{})
+	r.queryOpt = true
+	for _, q := range routeQueryNames(route) {
+		if value, ok := route.Params[q]; ok {
+			query[q] = value
+			r.queryOpt = r.queryOpt && value.Opt
+		}
+	}
+	if len(query) > 0 {
+		r.query = name + "Query"
+		declare(r.query, query)
+	}
+
+	if route.RequestBody != nil {
+		r.request = name + "Request"
+		declare(r.request, route.RequestBody)
+	}
+
+	for _, statusCode := range sortedStatusCodes(route.ResponseBodies) {
+		body := route.ResponseBodies[statusCode]
+		if body == nil {
+			continue
+		}
+		response := name + "Response" + strconv.Itoa(statusCode)
+		declare(response, body)
+		if statusCode >= 200 && statusCode < 300 {
+			r.responses = append(r.responses, response)
+		}
+	}
+
+	return r
+}
+
+// writeTSEvent declares the headers and the payload of an event, eg. CreatedEventHeaders and CreatedEvent.
+func writeTSEvent(buf *bytes.Buffer, event *DocEvent, name string) {
+	comment := "// " + event.Name + " [" + eventLabel(event) + " " + event.EventName + "]"
+	if len(event.Headers) > 0 {
+		headers := make(map[string]interface{}, len(event.Headers))
+		for key, value := range event.Headers {
+			headers[key] = value
+		}
+		writeTSDeclaration(buf, comment, name+"EventHeaders", headers)
+		comment = ""
+	}
+	if event.Payload != nil {
+		writeTSDeclaration(buf, comment, name+"Event", event.Payload)
+	}
+}
+
+// writeTSDeclaration writes objects as interfaces, and the other trees as type aliases.
+func writeTSDeclaration(buf *bytes.Buffer, comment string, name string, tree interface{}) {
+	buf.WriteString("\n")
+	if comment != "" {
+		buf.WriteString(comment + "\n")
+	}
+	if _, ok := tree.(map[string]interface{}); ok {
+		buf.WriteString("export interface " + name + " " + tsType(tree, "") + "\n")
+		return
+	}
+	buf.WriteString("export type " + name + " = " + tsType(tree, "") + ";\n")
+}
+
+func writeTSFetch(buf *bytes.Buffer, r *tsRoute) {
+	fnName := strings.ToLower(r.name[:1]) + r.name[1:]
+	if tsReservedWords[fnName] {
+		fnName += "Route"
+	}
+
+	path := routePath(r.group, r.route, func(name string) string {
+		return "${encodeURIComponent(String(args.params" + tsAccess(name) + "))}"
+	})
+	path = "`" + strings.Replace(path, "`", "\\`", -1) + "`"
+
+	args := make([]string, 0, 3)
+	if r.params != "" {
+		args = append(args, "params: "+r.params)
+	}
+	query := "undefined"
+	if r.query != "" {
+		if r.queryOpt {
+			args = append(args, "query?: "+r.query)
+		} else {
+			args = append(args, "query: "+r.query)
+		}
+		query = "args.query"
+	}
+	body := "undefined"
+	if r.request != "" {
+		args = append(args, "body: "+r.request)
+		body = "args.body"
+	}
+
+	result := "void"
+	if len(r.responses) > 0 {
+		result = strings.Join(r.responses, " | ")
+	}
+
+	signature := "options: FetchOptions"
+	if len(args) > 0 {
+		signature += ", args: { " + strings.Join(args, "; ") + " }"
+	}
+
+	buf.WriteString("\n// " + fnName + " sends " + r.route.Method + " " + r.group.Prefix + r.route.Path + "\n")
+	buf.WriteString("export function " + fnName + "(" + signature + "): Promise<" + result + "> {\n")
+	buf.WriteString("  return request(options, " + tsString(r.route.Method) + ", " + path + ", " + query + ", " + body + ");\n")
+	buf.WriteString("}\n")
+}
+
+// tsType returns the TypeScript type of a document tree, the objects are indented by indent.
+func tsType(tree interface{}, indent string) string {
+	switch t := tree.(type) {
+	case *DocValue:
+		result := tsValueType(t)
+		if t.Nullable || t.Null {
+			result += " | null"
+		}
+		return result
+
+	case *DocArray:
+		if t.Item == nil {
+			return "unknown[]"
+		}
+		return tsArrayType(tsType(t.Item, indent), isTSUnion(t.Item))
+
+	case *DocOneOf:
+		variants := make([]string, 0, len(t.Variants))
+		for _, variant := range t.Variants {
+			variants = append(variants, tsType(variant, indent))
+		}
+		if len(variants) == 0 {
+			return "unknown"
+		}
+		return strings.Join(variants, " | ")
+
+	case *DocRef:
+		return exportedName(t.Name)
+
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for key := range t {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		lines := []string{"{"}
+		for _, key := range keys {
+			optional := ""
+			if v, ok := t[key].(*DocValue); ok && v.Opt {
+				optional = "?"
+			}
+			lines = append(lines, indent+"  "+tsKey(key)+optional+": "+tsType(t[key], indent+"  ")+";")
+		}
+		lines = append(lines, indent+"}")
+		return strings.Join(lines, "\n")
+
+	default:
+		return "unknown"
+	}
+}
+
+// tsValueType returns the type of a value, the union of its literals for enums. Query arrays are arrays of strings.
+func tsValueType(v *DocValue) string {
+	typ := v.APIMDType
+	if typ == "array" {
+		typ = "string"
+	}
+
+	result := "unknown"
+	switch typ {
+	case "string", "number", "boolean":
+		result = typ
+	}
+	if len(v.Enum) > 0 {
+		literals := make([]string, 0, len(v.Enum))
+		for _, member := range v.Enum {
+			switch {
+			case typ == "number" && isJSONNumber(json.Number(member)),
+				typ == "boolean" && (member == "true" || member == "false"):
+				literals = append(literals, member)
+			default:
+				literals = append(literals, tsString(member))
+			}
+		}
+		result = strings.Join(literals, " | ")
+	}
+
+	if v.APIMDType == "array" {
+		return tsArrayType(result, len(v.Enum) > 1)
+	}
+
+	return result
+}
+
+func tsArrayType(item string, union bool) string {
+	if union {
+		return "(" + item + ")[]"
+	}
+
+	return item + "[]"
+}
+
+// isTSUnion tells whether the type of tree is a union, which has to be parenthesized as an array item.
+func isTSUnion(tree interface{}) bool {
+	switch t := tree.(type) {
+	case *DocValue:
+		return t.Nullable || t.Null || len(t.Enum) > 1
+	case *DocOneOf:
+		return len(t.Variants) > 1
+	default:
+		return false
+	}
+}
+
+func tsKey(key string) string {
+	if tsIdentifierRegex.MatchString(key) {
+		return key
+	}
+
+	return tsString(key)
+}
+
+func tsAccess(key string) string {
+	if tsIdentifierRegex.MatchString(key) {
+		return "." + key
+	}
+
+	return "[" + tsString(key) + "]"
+}
+
+func tsString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRenderTypeScript(t *testing.T) {
+	doc := &Document{
+		Categories: []*DocCategory{
+			{Name: categoryHTTP, Groups: []*DocGroup{{
+				Name:   "Users",
+				Prefix: "/api",
+				Routes: []*DocRoute{{
+					Name:   "Update",
+					Method: http.MethodPut,
+					Path:   "/users/{id}{?tags,limit}",
+					Params: map[string]*DocValue{
+						"id":    {Value: "1", APIMDType: "string"},
+						"tags":  {Value: "a,b", APIMDType: "array", Enum: []string{"a", "b"}},
+						"limit": {Value: "10", APIMDType: "number", Opt: true},
+					},
+					RequestBody: map[string]interface{}{
+						"user":  &DocRef{Name: "User"},
+						"admin": &DocValue{Value: "true", APIMDType: "boolean"},
+					},
+					ResponseBodies: map[int]interface{}{
+						http.StatusOK:       &DocArray{Item: &DocRef{Name: "User"}},
+						http.StatusNotFound: nil,
+					},
+				}},
+			}}},
+			{Name: categoryFiredEvents, Groups: []*DocGroup{{
+				Name: "Events",
+				Events: []*DocEvent{{
+					Name:      "Created",
+					Direction: EventFired,
+					EventName: "/user/created",
+					Headers:   map[string]*DocValue{"trace_id": {Value: "t1", APIMDType: "string"}},
+					Payload:   &DocValue{Value: "1", APIMDType: "string"},
+				}},
+			}}},
+		},
+		DataStructures: []*DocDataStructure{{Name: "User", Value: map[string]interface{}{
+			"age":        &DocValue{Value: "30", APIMDType: "number", Enum: []string{"30", "40"}},
+			"deleted_at": &DocValue{Null: true, Nullable: true, APIMDType: "string"},
+		}}},
+	}
+
+	got := string(RenderTypeScript(doc, true))
+
+	for _, want := range []string{
+		"export interface User {\n" +
+			"  age: 30 | 40;\n" +
+			"  deleted_at: string | null;\n" +
+			"}\n",
+		"// Update [PUT /api/users/{id}{?tags,limit}]\n" +
+			"export interface UpdateParams {\n" +
+			"  id: string;\n" +
+			"}\n",
+		"export interface UpdateQuery {\n" +
+			"  limit?: number;\n" +
+			"  tags: (\"a\" | \"b\")[];\n" +
+			"}\n",
+		"export interface UpdateRequest {\n" +
+			"  admin: boolean;\n" +
+			"  user: User;\n" +
+			"}\n",
+		"export type UpdateResponse200 = User[];\n",
+		"// Created [Fired GEB event /user/created]\n" +
+			"export interface CreatedEventHeaders {\n" +
+			"  trace_id: string;\n" +
+			"}\n",
+		"export type CreatedEvent = string;\n",
+		"export function update(options: FetchOptions, args: { params: UpdateParams; query: UpdateQuery; body: UpdateRequest }): Promise<UpdateResponse200> {\n" +
+			"  return request(options, \"PUT\", `/api/users/${encodeURIComponent(String(args.params.id))}`, args.query, args.body);\n" +
+			"}\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want:\n%v\ngot:\n%v", want, got)
+		}
+	}
+	if strings.Contains(got, "UpdateResponse404") || strings.Contains(got, "function created") {
+		t.Errorf("unexpected declarations:\n%v", got)
+	}
+
+	if got := string(RenderTypeScript(doc, false)); strings.Contains(got, "FetchOptions") {
+		t.Errorf("want no fetch functions without fetch")
+	}
+}
+
+func TestTSType(t *testing.T) {
+	for _, data := range []struct {
+		Tree interface{}
+		Want string
+	}{
+		{Tree: &DocValue{APIMDType: "string", Enum: []string{"a", `b"`}}, Want: `"a" | "b\""`},
+		{Tree: &DocValue{APIMDType: "boolean", Enum: []string{"true"}}, Want: `true`},
+		{Tree: &DocValue{APIMDType: "number", Enum: []string{"x"}}, Want: `"x"`},
+		{Tree: &DocArray{Item: &DocValue{APIMDType: "number", Nullable: true}}, Want: `(number | null)[]`},
+		{Tree: &DocArray{Item: &DocArray{Item: &DocRef{Name: "User"}}}, Want: `User[][]`},
+		{Tree: &DocOneOf{Variants: []interface{}{map[string]interface{}{"card": &DocValue{APIMDType: "string"}}, &DocValue{APIMDType: "number"}}}, Want: "{\n  card: string;\n} | number"},
+		{Tree: map[string]interface{}{"a-b": &DocValue{APIMDType: "string", Opt: true}}, Want: "{\n  \"a-b\"?: string;\n}"},
+	} {
+		if got := tsType(data.Tree, ""); got != data.Want {
+			t.Errorf("want: %v got: %v", data.Want, got)
+		}
+	}
+}
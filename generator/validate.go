@@ -0,0 +1,171 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ValidateBody checks a JSON body against a document tree: the types of the values, the presence of the required
+// fields, the enums, the array constraints and the One Of variants. It returns the problems with the JSON paths of
+// the values, eg. "$.user.id: missing required field". Fields which are not documented are not reported, as the
+// zero values are left out of the documents by default.
+func ValidateBody(doc *Document, tree interface{}, body []byte) ([]string, error) {
+	var data interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	err := decoder.Decode(&data)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid JSON body")
+	}
+
+	return validateTree(doc, tree, data, "$", make(map[string]bool)), nil
+}
+
+// validateTree returns the problems of data, resolving is the set of the data structures being resolved at a path,
+// to stop at references which refer to themselves without nesting.
+func validateTree(doc *Document, tree interface{}, data interface{}, path string, resolving map[string]bool) []string {
+	switch t := tree.(type) {
+	case *DocValue:
+		return validateValue(t, data, path)
+
+	case *DocArray:
+		items, ok := data.([]interface{})
+		if !ok {
+			return []string{path + ": want array, got " + jsonKind(data)}
+		}
+		problems := make([]string, 0)
+		if t.MinItems > 0 && len(items) < t.MinItems {
+			problems = append(problems, fmt.Sprintf("%v: want at least %v items, got %v", path, t.MinItems, len(items)))
+		}
+		if t.MaxItems > 0 && len(items) > t.MaxItems {
+			problems = append(problems, fmt.Sprintf("%v: want at most %v items, got %v", path, t.MaxItems, len(items)))
+		}
+		if t.UniqueItems {
+			seen := make(map[string]bool, len(items))
+			for _, item := range items {
+				b, _ := json.Marshal(item)
+				key := string(b)
+				if seen[key] {
+					problems = append(problems, path+": items are not unique")
+					break
+				}
+				seen[key] = true
+			}
+		}
+		if t.Item != nil {
+			for i, item := range items {
+				problems = append(problems, validateTree(doc, t.Item, item, path+"["+strconv.Itoa(i)+"]", resolving)...)
+			}
+		}
+		return problems
+
+	case *DocOneOf:
+		for _, variant := range t.Variants {
+			if len(validateTree(doc, variant, data, path, resolving)) == 0 {
+				return []string{}
+			}
+		}
+		return []string{path + ": does not match any of the One Of variants"}
+
+	case *DocRef:
+		key := t.Name + " " + path
+		if resolving[key] {
+			return []string{}
+		}
+		for _, ds := range doc.DataStructures {
+			if ds.Name == t.Name {
+				resolving[key] = true
+				defer delete(resolving, key)
+				return validateTree(doc, ds.Value, data, path, resolving)
+			}
+		}
+		return []string{}
+
+	case map[string]interface{}:
+		object, ok := data.(map[string]interface{})
+		if !ok {
+			return []string{path + ": want object, got " + jsonKind(data)}
+		}
+		keys := make([]string, 0, len(t))
+		for key := range t {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		problems := make([]string, 0)
+		for _, key := range keys {
+			value, ok := object[key]
+			if !ok {
+				if v, isValue := t[key].(*DocValue); !isValue || !v.Opt {
+					problems = append(problems, path+"."+key+": missing required field")
+				}
+				continue
+			}
+			problems = append(problems, validateTree(doc, t[key], value, path+"."+key, resolving)...)
+		}
+		return problems
+
+	default:
+		return []string{}
+	}
+}
+
+func validateValue(v *DocValue, data interface{}, path string) []string {
+	if data == nil {
+		if v.Nullable || v.Null {
+			return []string{}
+		}
+		return []string{path + ": want " + v.APIMDType + ", got null"}
+	}
+
+	want := v.APIMDType
+	switch want {
+	case "string", "number", "boolean":
+	case "array":
+		// query arrays are documented as a value
+		if _, ok := data.([]interface{}); !ok {
+			return []string{path + ": want array, got " + jsonKind(data)}
+		}
+		return []string{}
+	default:
+		return []string{}
+	}
+	if got := jsonKind(data); got != want {
+		return []string{path + ": want " + want + ", got " + got}
+	}
+
+	if len(v.Enum) > 0 {
+		value := fmt.Sprint(data)
+		for _, member := range v.Enum {
+			if member == value {
+				return []string{}
+			}
+		}
+		return []string{fmt.Sprintf("%v: %q is not one of the enum values", path, value)}
+	}
+
+	return []string{}
+}
+
+// jsonKind returns the APIMDType of decoded JSON, or object or null.
+func jsonKind(data interface{}) string {
+	switch data.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case json.Number, float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
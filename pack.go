@@ -13,4 +13,11 @@ func main() {
 	}
 
 	_ = ioutil.WriteFile("./generator/API.md.tmpl.go", []byte("package generator\n\nconst apimdTmpl="+strconv.Quote(string(apimd))), 0777)
+
+	html, err := ioutil.ReadFile("./generator/API.html.tmpl")
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+	_ = ioutil.WriteFile("./generator/API.html.tmpl.go", []byte("package generator\n\nconst htmlTmpl="+strconv.Quote(string(html))), 0777)
 }